@@ -0,0 +1,180 @@
+package qrcode
+
+// CodewordModule tells which codeword and bit a module in the encoding region belongs to.
+type CodewordModule struct {
+	// Block index of the block in order of groups, starts from 0.
+	Block int
+
+	// Codeword index of the codeword in block, data codewords come first and
+	// then error correction codewords.
+	Codeword int
+
+	// Bit position in codeword, 7 is the most significant bit. The final data
+	// codeword of M1 and M3 Micro QR Code has only 4 bits (7 to 4).
+	Bit int
+
+	// EC is true if the codeword is an error correction codeword.
+	EC bool
+}
+
+// CodewordMap maps each module in the encoding region of a symbol to its codeword,
+// it's used to find out which blocks are damaged by a logo or to draw the interleaving.
+type CodewordMap struct {
+	width, height int
+
+	// modules are indexed by x*height+y, Block is -1 for function modules and
+	// remainder bits which belong to no codeword.
+	modules []CodewordModule
+}
+
+// CodewordMap returns which block, codeword and bit each module of data and
+// error correction codewords belongs to. It's calculated from the final matrix,
+// so it's not cheap and the result should be kept by caller.
+func (q *QRCode) CodewordMap() *CodewordMap {
+	w := q.mat.Width()
+	switch {
+	case q.micro != nil:
+		return newCodewordMap(q.mat, dataModules(q.mat, w-1, -1), microBitAt(q.micro))
+	case q.rmqr != nil:
+		return newCodewordMap(q.mat, dataModules(q.mat, w-2, -1), interleavedBitAt(q.v.Groups))
+	default:
+		return newCodewordMap(q.mat, dataModules(q.mat, w-1, 6), interleavedBitAt(q.v.Groups))
+	}
+}
+
+// newCodewordMap maps modules at positions in placement order to codewords by bitAt.
+func newCodewordMap(m *Matrix, positions []loc, bitAt func(pos int) (CodewordModule, bool)) *CodewordMap {
+	w, h := m.Width(), m.Height()
+	cm := &CodewordMap{width: w, height: h, modules: make([]CodewordModule, w*h)}
+	for i := range cm.modules {
+		cm.modules[i].Block = -1
+	}
+
+	for pos, l := range positions {
+		if module, ok := bitAt(pos); ok {
+			cm.modules[l.X*h+l.Y] = module
+		}
+	}
+
+	return cm
+}
+
+// At returns the codeword module at (x, y), ok is false if the module belongs
+// to no codeword, such as function patterns and remainder bits.
+func (cm *CodewordMap) At(x, y int) (m CodewordModule, ok bool) {
+	if x < 0 || x >= cm.width || y < 0 || y >= cm.height {
+		return m, false
+	}
+
+	m = cm.modules[x*cm.height+y]
+	return m, m.Block >= 0
+}
+
+// Iterate visits all modules which belong to a codeword, in the order of rows.
+func (cm *CodewordMap) Iterate(fn func(x, y int, m CodewordModule)) {
+	for y := 0; y < cm.height; y++ {
+		for x := 0; x < cm.width; x++ {
+			if m, ok := cm.At(x, y); ok {
+				fn(x, y, m)
+			}
+		}
+	}
+}
+
+// codewordRef refers to a codeword in block.
+type codewordRef struct {
+	block, index int
+	ec           bool
+}
+
+// interleavedOrder returns codewords in the order they are placed in the symbol,
+// data codewords of all blocks are interleaved and followed by interleaved EC
+// codewords, the same as arrangeBits does.
+func interleavedOrder(groups []group) []codewordRef {
+	type block struct{ numData, numEC int }
+
+	blocks := make([]block, 0, 8)
+	maxData, total := 0, 0
+	for _, g := range groups {
+		for i := 0; i < g.NumBlocks; i++ {
+			blocks = append(blocks, block{numData: g.NumDataCodewords, numEC: g.ECBlockwordsPerBlock})
+		}
+		maxData = max(maxData, g.NumDataCodewords)
+		total += g.NumBlocks * (g.NumDataCodewords + g.ECBlockwordsPerBlock)
+	}
+
+	order := make([]codewordRef, 0, total)
+	for i := 0; i < maxData; i++ {
+		for j, b := range blocks {
+			if i < b.numData {
+				order = append(order, codewordRef{block: j, index: i})
+			}
+		}
+	}
+	for i := 0; len(blocks) > 0 && i < blocks[0].numEC; i++ {
+		for j, b := range blocks {
+			order = append(order, codewordRef{block: j, index: b.numData + i, ec: true})
+		}
+	}
+
+	return order
+}
+
+// interleavedBitAt returns the codeword module of bit pos in the placement
+// order of QR Code or rMQR Code.
+func interleavedBitAt(groups []group) func(pos int) (CodewordModule, bool) {
+	order := interleavedOrder(groups)
+	return func(pos int) (CodewordModule, bool) {
+		if pos/8 >= len(order) {
+			return CodewordModule{}, false
+		}
+
+		ref := order[pos/8]
+		return CodewordModule{Block: ref.block, Codeword: ref.index, Bit: 7 - pos%8, EC: ref.ec}, true
+	}
+}
+
+// microBitAt returns the codeword module of bit pos in the placement order of
+// Micro QR Code, which has only one block, the final data codeword of M1 and M3 is 4 bits.
+func microBitAt(v *microVersion) func(pos int) (CodewordModule, bool) {
+	numData := v.NumDataCodewords()
+	return func(pos int) (CodewordModule, bool) {
+		if pos < v.DataBits {
+			return CodewordModule{Codeword: pos / 8, Bit: 7 - pos%8}, true
+		}
+		if pos -= v.DataBits; pos/8 < v.NumECCodewords {
+			return CodewordModule{Codeword: numData + pos/8, Bit: 7 - pos%8, EC: true}, true
+		}
+
+		return CodewordModule{}, false
+	}
+}
+
+// dataModules returns the modules of encoding region in placement order, in 2 modules
+// wide columns from column right, moving upwards and downwards alternately. Column
+// timingColumn is skipped, -1 means no column to skip. Modules in m which are not set
+// (prefilled matrix) or set as data (final matrix) are both treated as encoding region.
+func dataModules(m *Matrix, right, timingColumn int) []loc {
+	locs := make([]loc, 0, m.Width()*m.Height())
+	upward := true
+	for ; right >= 1; right -= 2 {
+		if right == timingColumn {
+			right--
+		}
+		for i := 0; i < m.Height(); i++ {
+			y := i
+			if upward {
+				y = m.Height() - 1 - i
+			}
+			for x := right; x >= right-1; x-- {
+				if v, _ := m.at(x, y); v.qrtype() != QRType_INIT && v.qrtype() != QRType_DATA {
+					continue
+				}
+				locs = append(locs, loc{X: x, Y: y})
+			}
+		}
+		upward = !upward
+	}
+
+	return locs
+}
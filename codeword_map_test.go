@@ -0,0 +1,128 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yeqown/reedsolomon"
+)
+
+// blocksOf reads codewords of each block from unmasked matrix by CodewordMap.
+func blocksOf(t *testing.T, qrc *QRCode, moduloFn moduloFunc) map[int]map[int]byte {
+	t.Helper()
+
+	blocks := make(map[int]map[int]byte)
+	qrc.CodewordMap().Iterate(func(x, y int, m CodewordModule) {
+		v, err := qrc.mat.at(x, y)
+		require.NoError(t, err)
+		require.Equal(t, QRType_DATA, v.qrtype())
+
+		if blocks[m.Block] == nil {
+			blocks[m.Block] = make(map[int]byte)
+		}
+		codeword := blocks[m.Block][m.Codeword]
+		if v.qrbool() != moduloFn(x, y) {
+			codeword |= 1 << m.Bit
+		}
+		blocks[m.Block][m.Codeword] = codeword
+	})
+
+	return blocks
+}
+
+func Test_QRCode_CodewordMap(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts []EncodeOption
+	}{
+		{name: "version 1", text: "codeword map", opts: []EncodeOption{WithVersion(1), WithErrorCorrectionLevel(ErrorCorrectionLow)}},
+		// 5-Q has 2 groups of 2 blocks, with 15 and 16 data codewords.
+		{name: "version 5 two groups", text: strings.Repeat("codeword", 7), opts: []EncodeOption{WithVersion(5), WithErrorCorrectionLevel(ErrorCorrectionQuart)}},
+		{name: "version 7", text: strings.Repeat("codeword", 7), opts: []EncodeOption{WithVersion(7), WithErrorCorrectionLevel(ErrorCorrectionHighest)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qrc, err := NewWith(tt.text, tt.opts...)
+			require.NoError(t, err)
+
+			// expected blocks are encoded again.
+			require.NoError(t, qrc.applyEncoder())
+			dataBlocks, err := qrc.dataEncoding()
+			require.NoError(t, err)
+
+			blocks := blocksOf(t, qrc, getModuloFunc(maskPatternModulo(qrc.maskPattern)))
+			require.Len(t, blocks, len(dataBlocks))
+			for i, b := range dataBlocks {
+				full := reedsolomon.Encode(b.Data, b.NumECBlock).Bytes()
+				require.Len(t, blocks[i], len(full), "block %d", i)
+				for j, want := range full {
+					assert.Equal(t, want, blocks[i][j], "block %d codeword %d", i, j)
+				}
+			}
+
+			// remainder bits and function modules belong to no codeword.
+			cm := qrc.CodewordMap()
+			_, ok := cm.At(0, 0)
+			assert.False(t, ok)
+			_, ok = cm.At(-1, 0)
+			assert.False(t, ok)
+			n := 0
+			cm.Iterate(func(int, int, CodewordModule) { n++ })
+			assert.Equal(t, qrc.dataBSet.Len()-qrc.v.RemainderBits, n)
+		})
+	}
+}
+
+func Test_QRCode_CodewordMap_EC(t *testing.T) {
+	qrc, err := NewWith("CODEWORD", WithVersion(1), WithErrorCorrectionLevel(ErrorCorrectionHighest))
+	require.NoError(t, err)
+
+	// 1-H has 9 data codewords and 17 EC codewords in one block.
+	data, ec := 0, 0
+	qrc.CodewordMap().Iterate(func(x, y int, m CodewordModule) {
+		assert.Equal(t, 0, m.Block)
+		assert.Equal(t, m.Codeword >= 9, m.EC)
+		if m.EC {
+			ec++
+		} else {
+			data++
+		}
+	})
+	assert.Equal(t, 9*8, data)
+	assert.Equal(t, 17*8, ec)
+}
+
+func Test_QRCode_CodewordMap_MicroAndRMQR(t *testing.T) {
+	qrc, err := NewRMQR(strings.Repeat("rMQR", 15), WithErrorCorrectionLevel(ErrorCorrectionHighest))
+	require.NoError(t, err)
+	require.Greater(t, qrc.v.TotalNumBlocks(), 1)
+
+	blocks := blocksOf(t, qrc, modulo4Func)
+	require.Len(t, blocks, qrc.v.TotalNumBlocks())
+	ec := qrc.v.Groups[0].ECBlockwordsPerBlock
+	for i, b := range blocks {
+		full := make([]byte, len(b))
+		for j := range full {
+			full[j] = b[j]
+		}
+		// no error to correct means codewords are read in right order.
+		corrected, err := correctReedSolomon(full, ec)
+		assert.NoError(t, err, "block %d", i)
+		assert.Equal(t, 0, corrected, "block %d", i)
+	}
+
+	// M1 has 20 data bits (the final codeword is 4 bits) and 2 EC codewords.
+	qrc, err = NewMicro("12345", WithVersion(1), WithErrorCorrectionLevel(ErrorCorrectionLow))
+	require.NoError(t, err)
+	bits := map[int]int{}
+	qrc.CodewordMap().Iterate(func(x, y int, m CodewordModule) {
+		assert.Equal(t, 0, m.Block)
+		assert.Equal(t, m.Codeword >= 3, m.EC)
+		bits[m.Codeword]++
+	})
+	assert.Equal(t, map[int]int{0: 8, 1: 8, 2: 4, 3: 8, 4: 8}, bits)
+}
@@ -0,0 +1,171 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+)
+
+// BlockDamage describes how many codewords of a block are covered.
+type BlockDamage struct {
+	// Block index of the block in order of groups, starts from 0.
+	Block int
+
+	// Damaged the number of codewords which have at least one module covered.
+	Damaged int
+
+	// Correctable the number of codewords the block could correct, codewords
+	// reserved for misdecode protection are excluded.
+	Correctable int
+}
+
+// Margin returns how many more codewords of block could be damaged, negative
+// means the block could not be corrected.
+func (b BlockDamage) Margin() int {
+	return b.Correctable - b.Damaged
+}
+
+// DamageReport is the result of AnalyzeDamage.
+type DamageReport struct {
+	// Blocks damage of each block in order.
+	Blocks []BlockDamage
+
+	// FunctionModules the number of covered function modules by type, such as
+	// QRType_FINDER and QRType_TIMING.
+	FunctionModules map[qrtype]int
+
+	// OK is true if every block is still correctable and no function pattern
+	// but alignment pattern is covered.
+	OK bool
+
+	// Margin is the minimum margin of all blocks.
+	Margin int
+}
+
+// AnalyzeDamage reports how many codewords of each block are destroyed by covered
+// areas, such as a logo, in module coordinates. A codeword is treated as destroyed
+// if any of its modules is covered, which is the worst case for overlays.
+func (q *QRCode) AnalyzeDamage(covered ...image.Rectangle) *DamageReport {
+	var numEC []int
+	if q.micro != nil {
+		numEC = []int{q.micro.NumECCodewords}
+	} else {
+		numEC = blockECCodewords(q.v.Groups)
+	}
+
+	return analyzeDamage(q.mat, q.CodewordMap(), numEC, q.misdecodeProtection(), covered)
+}
+
+// AnalyzeDamage reports the damage of covered areas to a regular QR Code matrix like
+// QRCode.AnalyzeDamage, version and error correction level are read from the matrix,
+// so it could be used by writers which only have the Matrix.
+func AnalyzeDamage(mat Matrix, covered ...image.Rectangle) (*DamageReport, error) {
+	m := &mat
+	dimension := m.Width()
+	if dimension != m.Height() || dimension < 21 || dimension > 177 || (dimension-17)%4 != 0 {
+		return nil, fmt.Errorf("analyze damage: invalid dimension %dx%d", m.Width(), m.Height())
+	}
+
+	ec, _, err := decodeFormatInfo(m)
+	if err != nil {
+		return nil, fmt.Errorf("analyze damage: %w", err)
+	}
+
+	// function modules are located by prefilling a fresh matrix, since mat may
+	// be sampled from an image which has no module types.
+	layout := &QRCode{v: loadVersion((dimension-17)/4, ec), mat: newMatrix(dimension, dimension)}
+	layout.prefillMatrix()
+	cm := newCodewordMap(layout.mat, dataModules(layout.mat, dimension-1, 6), interleavedBitAt(layout.v.Groups))
+
+	return analyzeDamage(layout.mat, cm, blockECCodewords(layout.v.Groups), layout.misdecodeProtection(), covered), nil
+}
+
+// analyzeDamage counts damaged codewords of each block and covered function modules in m.
+func analyzeDamage(m *Matrix, cm *CodewordMap, numEC []int, p int, covered []image.Rectangle) *DamageReport {
+	damaged := make([]map[int]struct{}, len(numEC))
+	for i := range damaged {
+		damaged[i] = make(map[int]struct{})
+	}
+
+	report := &DamageReport{FunctionModules: make(map[qrtype]int)}
+	bound := image.Rect(0, 0, m.Width(), m.Height())
+	seen := make(map[loc]struct{})
+	for _, rect := range covered {
+		rect = rect.Canon().Intersect(bound)
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			for y := rect.Min.Y; y < rect.Max.Y; y++ {
+				if _, ok := seen[loc{X: x, Y: y}]; ok {
+					continue
+				}
+				seen[loc{X: x, Y: y}] = struct{}{}
+
+				if module, ok := cm.At(x, y); ok {
+					damaged[module.Block][module.Codeword] = struct{}{}
+					continue
+				}
+				if v, _ := m.at(x, y); v.qrtype() != QRType_INIT && v.qrtype() != QRType_DATA {
+					report.FunctionModules[v.qrtype()]++
+				}
+			}
+		}
+	}
+
+	report.OK = true
+	for i, n := range numEC {
+		b := BlockDamage{Block: i, Damaged: len(damaged[i]), Correctable: max(n-p, 0) / 2}
+		if i == 0 || b.Margin() < report.Margin {
+			report.Margin = b.Margin()
+		}
+		report.OK = report.OK && b.Margin() >= 0
+		report.Blocks = append(report.Blocks, b)
+	}
+	for typ := range report.FunctionModules {
+		if typ != QRType_ALIGNMENT {
+			report.OK = false
+		}
+	}
+
+	return report
+}
+
+// blockECCodewords returns the number of EC codewords of each block in order.
+func blockECCodewords(groups []group) []int {
+	numEC := make([]int, 0, 8)
+	for _, g := range groups {
+		for i := 0; i < g.NumBlocks; i++ {
+			numEC = append(numEC, g.ECBlockwordsPerBlock)
+		}
+	}
+
+	return numEC
+}
+
+// misdecodeProtection returns the number of EC codewords reserved for misdecode
+// protection (p in ISO/IEC 18004 Table 9), which could not be used to correct errors.
+func (q *QRCode) misdecodeProtection() int {
+	if q.micro != nil {
+		switch {
+		case q.micro.Ver == 1:
+			return 2
+		case q.micro.Ver == 2 && q.micro.ECLevel == ErrorCorrectionLow:
+			return 3
+		case q.micro.ECLevel == ErrorCorrectionLow || q.micro.Ver == 2:
+			return 2
+		}
+		return 0
+	}
+	if q.rmqr != nil {
+		return 0
+	}
+
+	switch {
+	case q.v.Ver == 1 && q.v.ECLevel == ErrorCorrectionLow:
+		return 3
+	case q.v.Ver == 1 && q.v.ECLevel == ErrorCorrectionMedium,
+		q.v.Ver == 2 && q.v.ECLevel == ErrorCorrectionLow:
+		return 2
+	case q.v.Ver == 1, q.v.Ver == 3 && q.v.ECLevel == ErrorCorrectionLow:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,125 @@
+// Package damage applies controlled damage to a qrcode.Matrix, such as random module
+// flips, blots, obliterated finder patterns and erased timing lines, to test decoders
+// and prints. Randomness is seeded, so the same damage could be reproduced.
+package damage
+
+import (
+	"image"
+	"math/rand"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+// Damage damages mat in place with rnd as the source of randomness.
+type Damage func(mat *qrcode.Matrix, rnd *rand.Rand)
+
+// Apply returns a damaged copy of mat, damages are applied in order and share the
+// randomness seeded by seed.
+func Apply(mat qrcode.Matrix, seed int64, damages ...Damage) qrcode.Matrix {
+	m := mat.Copy()
+	rnd := rand.New(rand.NewSource(seed))
+	for _, damage := range damages {
+		damage(m, rnd)
+	}
+
+	return *m
+}
+
+// Flips flips each module with the probability rate (0-1).
+func Flips(rate float64) Damage {
+	return func(mat *qrcode.Matrix, rnd *rand.Rand) {
+		mat.Iterate(qrcode.IterDirection_COLUMN, func(x, y int, v qrcode.QRValue) {
+			if rnd.Float64() < rate {
+				_ = mat.Paint(x, y, !v.IsSet())
+			}
+		})
+	}
+}
+
+// Blot paints a dark rectangle of width x height modules at a random position
+// inside the symbol, like a drop of ink.
+func Blot(width, height int) Damage {
+	return func(mat *qrcode.Matrix, rnd *rand.Rand) {
+		x, y := 0, 0
+		if n := mat.Width() - width + 1; n > 0 {
+			x = rnd.Intn(n)
+		}
+		if n := mat.Height() - height + 1; n > 0 {
+			y = rnd.Intn(n)
+		}
+
+		BlotAt(image.Rect(x, y, x+width, y+height), true)(mat, rnd)
+	}
+}
+
+// BlotAt paints all modules in rect dark or light.
+func BlotAt(rect image.Rectangle, dark bool) Damage {
+	return func(mat *qrcode.Matrix, _ *rand.Rand) {
+		rect := rect.Canon().Intersect(image.Rect(0, 0, mat.Width(), mat.Height()))
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			for y := rect.Min.Y; y < rect.Max.Y; y++ {
+				_ = mat.Paint(x, y, dark)
+			}
+		}
+	}
+}
+
+// Corner of finder pattern in regular QR Code.
+type Corner uint8
+
+const (
+	// TopLeft finder pattern.
+	TopLeft Corner = iota
+	// TopRight finder pattern.
+	TopRight
+	// BottomLeft finder pattern.
+	BottomLeft
+)
+
+// finderSize is the width and height of finder pattern in modules.
+const finderSize = 7
+
+// EraseFinder obliterates finder patterns at corners by painting them light,
+// modules are located by the layout of regular QR Code.
+func EraseFinder(corners ...Corner) Damage {
+	return func(mat *qrcode.Matrix, rnd *rand.Rand) {
+		for _, corner := range corners {
+			x, y := 0, 0
+			switch corner {
+			case TopRight:
+				x = mat.Width() - finderSize
+			case BottomLeft:
+				y = mat.Height() - finderSize
+			}
+
+			BlotAt(image.Rect(x, y, x+finderSize, y+finderSize), false)(mat, rnd)
+		}
+	}
+}
+
+// EraseTimingLines erases horizontal and vertical timing patterns between
+// finder patterns by painting them light, as regular QR Code lays them out.
+func EraseTimingLines() Damage {
+	return func(mat *qrcode.Matrix, rnd *rand.Rand) {
+		BlotAt(image.Rect(finderSize+1, finderSize-1, mat.Width()-finderSize-1, finderSize), false)(mat, rnd)
+		BlotAt(image.Rect(finderSize-1, finderSize+1, finderSize, mat.Height()-finderSize-1), false)(mat, rnd)
+	}
+}
+
+// SuccessRate damages mat with seeds from 0 to trials-1, and returns the rate of
+// trials which could still be decoded as text by qrcode.Decode.
+func SuccessRate(mat qrcode.Matrix, text string, trials int, damages ...Damage) float64 {
+	if trials <= 0 {
+		return 0
+	}
+
+	succeeded := 0
+	for seed := 0; seed < trials; seed++ {
+		result, err := qrcode.Decode(Apply(mat, int64(seed), damages...))
+		if err == nil && result.Text == text {
+			succeeded++
+		}
+	}
+
+	return float64(succeeded) / float64(trials)
+}
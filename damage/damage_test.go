@@ -0,0 +1,153 @@
+package damage
+
+import (
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const text = "https://github.com/yeqown/go-qrcode"
+
+func newMatrix(t testing.TB, opts ...qrcode.EncodeOption) qrcode.Matrix {
+	t.Helper()
+
+	qrc, err := qrcode.NewWith(text, opts...)
+	require.NoError(t, err)
+
+	return qrc.Matrix()
+}
+
+// diff returns the number of modules which differ in value.
+func diff(a, b qrcode.Matrix) int {
+	bitmap, n := b.Bitmap(), 0
+	for y, row := range a.Bitmap() {
+		for x, dark := range row {
+			if bitmap[y][x] != dark {
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+// allLight reports whether all modules in rect are light.
+func allLight(mat qrcode.Matrix, rect image.Rectangle) bool {
+	bitmap := mat.Bitmap()
+	for x := rect.Min.X; x < rect.Max.X; x++ {
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			if bitmap[y][x] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func Test_Apply(t *testing.T) {
+	mat := newMatrix(t)
+	origin := *mat.Copy()
+
+	damaged := Apply(mat, 1, Flips(0.05), Blot(3, 3))
+	assert.Equal(t, damaged, Apply(mat, 1, Flips(0.05), Blot(3, 3)), "the same seed")
+	assert.NotEqual(t, damaged, Apply(mat, 2, Flips(0.05), Blot(3, 3)))
+	assert.Equal(t, origin, mat, "mat is not modified")
+
+	// module types are kept.
+	damaged.Iterate(qrcode.IterDirection_ROW, func(x, y int, v qrcode.QRValue) {
+		assert.Equal(t, origin.Col(x)[y].Type(), v.Type())
+	})
+}
+
+func Test_Flips(t *testing.T) {
+	mat := newMatrix(t)
+	total := mat.Width() * mat.Height()
+
+	assert.Equal(t, 0, diff(mat, Apply(mat, 1, Flips(0))))
+	assert.Equal(t, total, diff(mat, Apply(mat, 1, Flips(1))))
+	assert.InDelta(t, total/10, diff(mat, Apply(mat, 1, Flips(0.1))), float64(total)/50)
+}
+
+func Test_Blot(t *testing.T) {
+	mat := newMatrix(t)
+
+	damaged := Apply(mat, 3, Blot(4, 5))
+	assert.Greater(t, diff(mat, damaged), 0)
+	assert.LessOrEqual(t, diff(mat, damaged), 20)
+
+	rect := image.Rect(10, 12, 5, 8)
+	damaged = Apply(mat, 0, BlotAt(rect, false))
+	assert.True(t, allLight(damaged, rect.Canon()))
+
+	// blot larger than symbol covers all.
+	damaged = Apply(mat, 0, Blot(100, 100), Flips(1))
+	assert.True(t, allLight(damaged, image.Rect(0, 0, mat.Width(), mat.Height())))
+}
+
+func Test_EraseFinder(t *testing.T) {
+	mat := newMatrix(t)
+	w := mat.Width()
+
+	damaged := Apply(mat, 0, EraseFinder(TopRight, BottomLeft))
+	assert.False(t, allLight(damaged, image.Rect(0, 0, 7, 7)))
+	assert.True(t, allLight(damaged, image.Rect(w-7, 0, w, 7)))
+	assert.True(t, allLight(damaged, image.Rect(0, w-7, 7, w)))
+	// 33 of 49 modules are dark in finder pattern.
+	assert.Equal(t, 2*33, diff(mat, damaged))
+}
+
+func Test_EraseTimingLines(t *testing.T) {
+	mat := newMatrix(t)
+	w := mat.Width()
+
+	damaged := Apply(mat, 0, EraseTimingLines())
+	assert.True(t, allLight(damaged, image.Rect(8, 6, w-8, 7)))
+	assert.True(t, allLight(damaged, image.Rect(6, 8, 7, w-8)))
+	// timing patterns start and end with dark modules.
+	assert.Equal(t, 2*((w-17)/2+1), diff(mat, damaged))
+}
+
+func Test_SuccessRate(t *testing.T) {
+	mat := newMatrix(t, qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionHighest))
+
+	assert.Equal(t, 1.0, SuccessRate(mat, text, 5))
+	assert.Equal(t, 1.0, SuccessRate(mat, text, 5, EraseFinder(TopLeft), EraseTimingLines()),
+		"finder and timing patterns are not used by qrcode.Decode")
+	assert.Equal(t, 0.0, SuccessRate(mat, text, 5, Flips(0.5)))
+	assert.Equal(t, 0.0, SuccessRate(mat, text, 0))
+}
+
+// Benchmark_Robustness reports the rate of symbols which are still decoded after
+// random flips, for each error correction level and mask pattern.
+func Benchmark_Robustness(b *testing.B) {
+	levels := []struct {
+		name  string
+		level qrcode.EncodeOption
+	}{
+		{name: "L", level: qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionLow)},
+		{name: "M", level: qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionMedium)},
+		{name: "Q", level: qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionQuart)},
+		{name: "H", level: qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionHighest)},
+	}
+
+	for _, lv := range levels {
+		for mask := 0; mask < 8; mask++ {
+			b.Run(fmt.Sprintf("%s/mask%d", lv.name, mask), func(b *testing.B) {
+				mat := newMatrix(b, qrcode.WithVersion(5), lv.level, qrcode.WithMaskPattern(mask))
+				b.ResetTimer()
+
+				rate := 0.0
+				for i := 0; i < b.N; i++ {
+					rate = SuccessRate(mat, text, 20, Flips(0.02))
+				}
+				b.ReportMetric(rate, "success")
+			})
+		}
+	}
+}
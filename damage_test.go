@@ -0,0 +1,115 @@
+package qrcode
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// invertArea inverts all data modules in rect, which is the worst case of a cover.
+func invertArea(m *Matrix, rect image.Rectangle) {
+	for x := rect.Min.X; x < rect.Max.X; x++ {
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			if v, _ := m.at(x, y); v.qrtype() == QRType_DATA {
+				if v.qrbool() {
+					_ = m.set(x, y, QRValue_DATA_V0)
+				} else {
+					_ = m.set(x, y, QRValue_DATA_V1)
+				}
+			}
+		}
+	}
+}
+
+func Test_QRCode_AnalyzeDamage(t *testing.T) {
+	qrc, err := NewWith(strings.Repeat("go-qrcode ", 4), WithVersion(5), WithErrorCorrectionLevel(ErrorCorrectionHighest))
+	require.NoError(t, err)
+
+	// 5-H has 4 blocks with 22 EC codewords.
+	report := qrc.AnalyzeDamage()
+	require.Len(t, report.Blocks, 4)
+	assert.True(t, report.OK)
+	assert.Equal(t, 11, report.Margin)
+	for _, b := range report.Blocks {
+		assert.Equal(t, 0, b.Damaged)
+		assert.Equal(t, 11, b.Correctable)
+	}
+
+	// a centered logo of 9x9 modules.
+	center := image.Rect(14, 14, 23, 23)
+	report = qrc.AnalyzeDamage(center)
+	assert.True(t, report.OK)
+	assert.Empty(t, report.FunctionModules)
+	for _, b := range report.Blocks {
+		assert.Greater(t, b.Damaged, 0)
+		assert.GreaterOrEqual(t, report.Margin, 0)
+		assert.LessOrEqual(t, report.Margin, b.Margin())
+	}
+
+	mat := qrc.mat.Copy()
+	invertArea(mat, center)
+	got, err := Decode(*mat)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("go-qrcode ", 4), got.Text)
+
+	// a logo which is too large.
+	report = qrc.AnalyzeDamage(image.Rect(9, 9, 28, 28))
+	assert.False(t, report.OK)
+	assert.Less(t, report.Margin, 0)
+
+	// alignment pattern is covered, which is allowed.
+	report = qrc.AnalyzeDamage(image.Rect(28, 28, 33, 33))
+	assert.True(t, report.OK)
+	assert.Equal(t, map[qrtype]int{QRType_ALIGNMENT: 25}, report.FunctionModules)
+
+	// finder pattern is covered.
+	report = qrc.AnalyzeDamage(image.Rect(-2, -2, 3, 3))
+	assert.False(t, report.OK)
+	assert.Equal(t, 9, report.FunctionModules[QRType_FINDER])
+}
+
+func Test_QRCode_AnalyzeDamage_Overlapping(t *testing.T) {
+	qrc, err := NewWith("CODEWORD", WithVersion(1), WithErrorCorrectionLevel(ErrorCorrectionHighest))
+	require.NoError(t, err)
+
+	rect := image.Rect(9, 9, 12, 12)
+	once := qrc.AnalyzeDamage(rect)
+	twice := qrc.AnalyzeDamage(rect, rect.Add(image.Pt(1, 1)), image.Rect(12, 12, 9, 9))
+	assert.Equal(t, once.Blocks, qrc.AnalyzeDamage(rect, image.Rect(12, 12, 9, 9)).Blocks)
+	assert.GreaterOrEqual(t, twice.Blocks[0].Damaged, once.Blocks[0].Damaged)
+
+	// 1-H has 17 EC codewords and 1 of them is for misdecode protection.
+	assert.Equal(t, 8, once.Blocks[0].Correctable)
+}
+
+func Test_QRCode_AnalyzeDamage_Micro(t *testing.T) {
+	qrc, err := NewMicro("MICRO", WithErrorCorrectionLevel(ErrorCorrectionMedium))
+	require.NoError(t, err)
+	require.NotNil(t, qrc.micro)
+
+	report := qrc.AnalyzeDamage()
+	require.Len(t, report.Blocks, 1)
+	assert.Equal(t, (qrc.micro.NumECCodewords-qrc.misdecodeProtection())/2, report.Blocks[0].Correctable)
+
+	m1, err := NewMicro("123", WithErrorCorrectionLevel(ErrorCorrectionLow))
+	require.NoError(t, err)
+	report = m1.AnalyzeDamage(image.Rect(9, 9, 10, 10))
+	assert.False(t, report.OK, "M1 provides error detection only")
+	assert.Equal(t, 0, report.Blocks[0].Correctable)
+}
+
+func Test_AnalyzeDamage(t *testing.T) {
+	qrc, err := NewWith(strings.Repeat("go-qrcode ", 20), WithErrorCorrectionLevel(ErrorCorrectionQuart))
+	require.NoError(t, err)
+
+	rects := []image.Rectangle{image.Rect(18, 18, 27, 27), image.Rect(0, 20, 3, 22)}
+	got, err := AnalyzeDamage(*qrc.mat, rects...)
+	require.NoError(t, err)
+	assert.Equal(t, qrc.AnalyzeDamage(rects...), got)
+
+	_, err = AnalyzeDamage(*newMatrix(22, 22))
+	assert.Error(t, err)
+}
@@ -0,0 +1,532 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// errNotEnoughBits means the data bit stream ends in the middle of a segment.
+var errNotEnoughBits = errors.New("not enough bits")
+
+// DecodeResult contains the payload and symbol information read from a Matrix by Decode.
+type DecodeResult struct {
+	// Text is the data of all segments, byte segments are converted into UTF-8 from
+	// the character set declared by ECI if it's supported, otherwise kept as they are.
+	Text string
+
+	// Segments are the segments as they are encoded in symbol, Kanji segments are
+	// converted into UTF-8 like Segment does.
+	Segments []Segment
+
+	// Version code 1-40.
+	Version int
+
+	// ECLevel error correction level.
+	ECLevel ecLevel
+
+	// MaskPattern the mask pattern reference 0-7.
+	MaskPattern int
+
+	// CorrectedCodewords the number of codewords corrected by Reed-Solomon error correction.
+	CorrectedCodewords int
+
+	// ECI is the last assignment number declared by ECI header, nil means no ECI header.
+	ECI *ECI
+
+	// StructuredAppend is the Structured Append header, nil means the symbol is not
+	// a part of Structured Append sequence.
+	StructuredAppend *StructuredAppendHeader
+}
+
+// StructuredAppendHeader is the Structured Append header read from a symbol.
+type StructuredAppendHeader struct {
+	Index  int  // index of the symbol in sequence, starts from 0.
+	Total  int  // total number of symbols in sequence.
+	Parity byte // parity of the whole data, XOR of all bytes.
+}
+
+// Decode reads the payload from a regular QR Code matrix, such as the one saved by Writer.
+// Format and version info are read with error correction, and data codewords are
+// corrected by Reed-Solomon error correction after being de-interleaved. All segment
+// modes, ECI, FNC1 and Structured Append headers are supported. Only the dark or light
+// value of modules is used, so mat could be sampled from an image as well.
+func Decode(mat Matrix) (*DecodeResult, error) {
+	m := &mat
+	dimension := m.Width()
+	if dimension != m.Height() || dimension < 21 || dimension > 177 || (dimension-17)%4 != 0 {
+		return nil, fmt.Errorf("decode: invalid dimension %dx%d", m.Width(), m.Height())
+	}
+
+	ec, maskPattern, err := decodeFormatInfo(m)
+	if err != nil {
+		return nil, err
+	}
+	ver := (dimension - 17) / 4
+	if ver >= 7 {
+		infoVer, err := decodeVersionInfo(m)
+		if err != nil {
+			return nil, err
+		}
+		if infoVer != ver {
+			return nil, fmt.Errorf("decode: version info (%d) mismatches dimension %d", infoVer, dimension)
+		}
+	}
+
+	v := loadVersion(ver, ec)
+	codewords := readCodewords(m, v, maskPattern)
+
+	data, corrected, err := deinterleaveAndCorrect(codewords, v)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DecodeResult{
+		Version:            ver,
+		ECLevel:            ec,
+		MaskPattern:        maskPattern,
+		CorrectedCodewords: corrected,
+	}
+	if err = result.parse(data); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	return result, nil
+}
+
+// formatInfoPositions returns positions of format info bits beside top-left finder
+// (pos 0 is the most significant bit), the same as fillFormatInfo.
+func formatInfoPositions(dimension int) (row, column [formatInfoBitsNum]loc) {
+	x, y := 0, dimension-1
+	for pos := 0; pos < formatInfoBitsNum; pos++ {
+		row[pos] = loc{X: x, Y: 8}
+		column[pos] = loc{X: 8, Y: y}
+
+		if x++; x == 6 {
+			x = 7
+		} else if x == 8 {
+			x = dimension - 8
+		}
+		if y--; y == dimension-8 {
+			y = 8
+		} else if y == 6 {
+			y = 5
+		}
+	}
+
+	return row, column
+}
+
+// readBits reads modules at locs as bits, the first one is the most significant bit.
+func readBits(m *Matrix, locs []loc) uint32 {
+	var value uint32
+	for _, l := range locs {
+		v, _ := m.at(l.X, l.Y)
+		value <<= 1
+		if v.qrbool() {
+			value |= 1
+		}
+	}
+
+	return value
+}
+
+// decodeFormatInfo reads both copies of format info, and chooses the closest
+// valid one which differs by at most 3 bits.
+func decodeFormatInfo(m *Matrix) (ec ecLevel, maskPattern int, err error) {
+	row, column := formatInfoPositions(m.Width())
+	copies := []uint32{readBits(m, row[:]), readBits(m, column[:])}
+
+	best, bestDistance := -1, 4
+	for id, seq := range formatBitSequence[:32] {
+		for _, c := range copies {
+			if d := bits.OnesCount32(c ^ seq.regular); d < bestDistance {
+				best, bestDistance = id, d
+			}
+		}
+	}
+	if best < 0 {
+		return 0, 0, fmt.Errorf("decode: invalid format info %015b", copies[0])
+	}
+
+	// EC level indicators are 01 (L), 00 (M), 11 (Q) and 10 (H).
+	ec = []ecLevel{ErrorCorrectionMedium, ErrorCorrectionLow,
+		ErrorCorrectionHighest, ErrorCorrectionQuart}[best>>3]
+
+	return ec, best & 0x7, nil
+}
+
+// decodeVersionInfo reads both copies of version info, and chooses the closest
+// valid one which differs by at most 3 bits.
+func decodeVersionInfo(m *Matrix) (int, error) {
+	dimension := m.Width()
+	topRight := make([]loc, 0, verInfoBitsNum)
+	bottomLeft := make([]loc, 0, verInfoBitsNum)
+	for j := 5; j >= 0; j-- {
+		for i := 1; i <= 3; i++ {
+			topRight = append(topRight, loc{X: dimension - 8 - i, Y: j})
+			bottomLeft = append(bottomLeft, loc{X: j, Y: dimension - 8 - i})
+		}
+	}
+	copies := []uint32{readBits(m, topRight), readBits(m, bottomLeft)}
+
+	best, bestDistance := -1, 4
+	for ver := 7; ver <= _VERSION_COUNT; ver++ {
+		for _, c := range copies {
+			if d := bits.OnesCount32(c ^ versionBitSequence[ver]); d < bestDistance {
+				best, bestDistance = ver, d
+			}
+		}
+	}
+	if best < 0 {
+		return 0, fmt.Errorf("decode: invalid version info %018b", copies[0])
+	}
+
+	return best, nil
+}
+
+// readCodewords reads all codewords in placement order with mask removed,
+// remainder bits are dropped.
+func readCodewords(m *Matrix, v version, maskPattern int) []byte {
+	// function modules are located by prefilling a fresh matrix.
+	dimension := v.Dimension()
+	layout := &QRCode{v: v, mat: newMatrix(dimension, dimension)}
+	layout.prefillMatrix()
+	moduloFn := getModuloFunc(maskPatternModulo(maskPattern))
+
+	total := 0
+	for _, g := range v.Groups {
+		total += g.NumBlocks * (g.NumDataCodewords + g.ECBlockwordsPerBlock)
+	}
+
+	codewords := make([]byte, total)
+	for pos, l := range dataModules(layout.mat, dimension-1, 6) {
+		if pos >= total*8 {
+			break
+		}
+		if value, _ := m.at(l.X, l.Y); value.qrbool() != moduloFn(l.X, l.Y) {
+			codewords[pos/8] |= 0x80 >> (pos % 8)
+		}
+	}
+
+	return codewords
+}
+
+// deinterleaveAndCorrect splits codewords into blocks reversely as arrangeBits does,
+// corrects each block and returns data codewords of all blocks in order.
+func deinterleaveAndCorrect(codewords []byte, v version) (data []byte, corrected int, err error) {
+	type block struct {
+		codewords []byte
+		numData   int
+	}
+
+	blocks := make([]block, 0, v.TotalNumBlocks())
+	numEC := 0
+	for _, g := range v.Groups {
+		for i := 0; i < g.NumBlocks; i++ {
+			blocks = append(blocks, block{
+				codewords: make([]byte, 0, g.NumDataCodewords+g.ECBlockwordsPerBlock),
+				numData:   g.NumDataCodewords,
+			})
+		}
+		numEC = g.ECBlockwordsPerBlock
+	}
+
+	for pos, ref := range interleavedOrder(v.Groups) {
+		blocks[ref.block].codewords = append(blocks[ref.block].codewords, codewords[pos])
+	}
+
+	data = make([]byte, 0, v.NumTotalCodewords())
+	for i, b := range blocks {
+		n, err := correctReedSolomon(b.codewords, numEC)
+		if err != nil {
+			return nil, 0, fmt.Errorf("decode: block %d: %w", i, err)
+		}
+		corrected += n
+		data = append(data, b.codewords[:b.numData]...)
+	}
+
+	return data, corrected, nil
+}
+
+// bitReader reads bits from bytes, the most significant bit first.
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) available() int {
+	return len(r.data)*8 - r.pos
+}
+
+func (r *bitReader) read(n int) (uint32, error) {
+	if n > r.available() {
+		return 0, errNotEnoughBits
+	}
+
+	var value uint32
+	for i := 0; i < n; i++ {
+		value <<= 1
+		if r.data[r.pos/8]&(0x80>>(r.pos%8)) != 0 {
+			value |= 1
+		}
+		r.pos++
+	}
+
+	return value, nil
+}
+
+// alphanumericCharset is the alphanumeric characters in order of their values.
+const alphanumericCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// parse parses segments and headers from data codewords until terminator.
+func (r *DecodeResult) parse(data []byte) error {
+	var (
+		reader = &bitReader{data: data}
+		text   strings.Builder
+		fnc1   bool
+	)
+
+loop:
+	for reader.available() >= 4 {
+		indicator, _ := reader.read(4)
+
+		var (
+			seg Segment
+			err error
+		)
+		switch indicator {
+		case 0b0000:
+			// terminator
+			break loop
+		case 0b0111:
+			var eci ECI
+			if eci, err = readECIDesignator(reader); err != nil {
+				return fmt.Errorf("ECI: %w", err)
+			}
+			r.ECI = &eci
+			continue
+		case 0b0011:
+			header, err := reader.read(16)
+			if err != nil {
+				return fmt.Errorf("structured append: %w", err)
+			}
+			r.StructuredAppend = &StructuredAppendHeader{
+				Index:  int(header >> 12),
+				Total:  int(header>>8&0xf) + 1,
+				Parity: byte(header),
+			}
+			continue
+		case 0b0101:
+			fnc1 = true
+			continue
+		case 0b1001:
+			// application indicator follows FNC1 in second position.
+			if _, err = reader.read(8); err != nil {
+				return fmt.Errorf("FNC1: %w", err)
+			}
+			fnc1 = true
+			continue
+		case 0b0001:
+			seg, err = r.readNumeric(reader)
+		case 0b0010:
+			seg, err = r.readAlphanumeric(reader)
+		case 0b0100:
+			seg, err = r.readByte(reader)
+		case 0b1000:
+			seg, err = r.readKanji(reader)
+		default:
+			return fmt.Errorf("unknown mode indicator %04b", indicator)
+		}
+		if err != nil {
+			return fmt.Errorf("%s segment: %w", getEncModeName(seg.Mode), err)
+		}
+		r.Segments = append(r.Segments, seg)
+
+		switch {
+		case seg.Mode == EncModeAlphanumeric && fnc1:
+			text.WriteString(unescapeFNC1(seg.Data))
+		case seg.Mode == EncModeByte && r.ECI != nil:
+			text.WriteString(decodeECI(seg.Data, *r.ECI))
+		default:
+			text.WriteString(seg.Data)
+		}
+	}
+	r.Text = text.String()
+
+	return nil
+}
+
+// readECIDesignator reads ECI designator in 1, 2 or 3 bytes, see eciHeader.
+func readECIDesignator(reader *bitReader) (ECI, error) {
+	first, err := reader.read(8)
+	if err != nil {
+		return 0, err
+	}
+
+	var rest uint32
+	switch {
+	case first&0x80 == 0:
+		return ECI(first), nil
+	case first&0xC0 == 0x80:
+		rest, err = reader.read(8)
+		return ECI((first&0x3F)<<8 | rest), err
+	case first&0xE0 == 0xC0:
+		rest, err = reader.read(16)
+		return ECI((first&0x1F)<<16 | rest), err
+	}
+
+	return 0, fmt.Errorf("invalid designator %08b", first)
+}
+
+func (r *DecodeResult) readNumeric(reader *bitReader) (Segment, error) {
+	seg := Segment{Mode: EncModeNumeric}
+	count, err := reader.read(charCountBits(r.Version, EncModeNumeric))
+	if err != nil {
+		return seg, err
+	}
+
+	digits := make([]byte, 0, count)
+	for remaining := int(count); remaining > 0; remaining -= 3 {
+		n := min(remaining, 3)
+		value, err := reader.read(n*3 + 1)
+		if err != nil {
+			return seg, err
+		}
+
+		s := fmt.Sprintf("%0*d", n, value)
+		if len(s) != n {
+			return seg, fmt.Errorf("invalid value %d of %d digits", value, n)
+		}
+		digits = append(digits, s...)
+	}
+	seg.Data = string(digits)
+
+	return seg, nil
+}
+
+func (r *DecodeResult) readAlphanumeric(reader *bitReader) (Segment, error) {
+	seg := Segment{Mode: EncModeAlphanumeric}
+	count, err := reader.read(charCountBits(r.Version, EncModeAlphanumeric))
+	if err != nil {
+		return seg, err
+	}
+
+	chars := make([]byte, 0, count)
+	for remaining := int(count); remaining > 0; remaining -= 2 {
+		if remaining == 1 {
+			value, err := reader.read(6)
+			if err != nil {
+				return seg, err
+			}
+			if value >= 45 {
+				return seg, fmt.Errorf("invalid value %d", value)
+			}
+			chars = append(chars, alphanumericCharset[value])
+			break
+		}
+
+		value, err := reader.read(11)
+		if err != nil {
+			return seg, err
+		}
+		if value >= 45*45 {
+			return seg, fmt.Errorf("invalid value %d", value)
+		}
+		chars = append(chars, alphanumericCharset[value/45], alphanumericCharset[value%45])
+	}
+	seg.Data = string(chars)
+
+	return seg, nil
+}
+
+func (r *DecodeResult) readByte(reader *bitReader) (Segment, error) {
+	seg := Segment{Mode: EncModeByte}
+	count, err := reader.read(charCountBits(r.Version, EncModeByte))
+	if err != nil {
+		return seg, err
+	}
+
+	data := make([]byte, count)
+	for i := range data {
+		value, err := reader.read(8)
+		if err != nil {
+			return seg, err
+		}
+		data[i] = byte(value)
+	}
+	seg.Data = string(data)
+
+	return seg, nil
+}
+
+func (r *DecodeResult) readKanji(reader *bitReader) (Segment, error) {
+	seg := Segment{Mode: EncModeKanji}
+	count, err := reader.read(charCountBits(r.Version, EncModeKanji))
+	if err != nil {
+		return seg, err
+	}
+
+	// reverse of encodeShiftJIS.
+	sjis := make([]byte, 0, count*2)
+	for i := 0; i < int(count); i++ {
+		value, err := reader.read(13)
+		if err != nil {
+			return seg, err
+		}
+
+		code := value/0xC0<<8 | value%0xC0
+		if code < 0x1F00 {
+			code += 0x8140
+		} else {
+			code += 0xC140
+		}
+		sjis = append(sjis, byte(code>>8), byte(code))
+	}
+
+	s, err := japanese.ShiftJIS.NewDecoder().Bytes(sjis)
+	if err != nil {
+		return seg, err
+	}
+	seg.Data = string(s)
+
+	return seg, nil
+}
+
+// unescapeFNC1 is the reverse of fnc1AlphanumericEscaper, '%%' stands for '%'
+// and a single '%' stands for FNC1 (Group Separator).
+func unescapeFNC1(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] != '%':
+			sb.WriteByte(s[i])
+		case i+1 < len(s) && s[i+1] == '%':
+			sb.WriteByte('%')
+			i++
+		default:
+			sb.WriteByte(_GS)
+		}
+	}
+
+	return sb.String()
+}
+
+// decodeECI converts data in the character set of eci into UTF-8,
+// data is kept as it is if the character set is not supported.
+func decodeECI(data string, eci ECI) string {
+	charset, ok := eciCharsets[eci]
+	if !ok {
+		return data
+	}
+
+	s, err := charset.NewDecoder().String(data)
+	if err != nil {
+		return data
+	}
+
+	return s
+}
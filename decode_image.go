@@ -0,0 +1,492 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+// errFinderNotFound means 3 finder patterns could not be located in image.
+var errFinderNotFound = errors.New("finder patterns not found")
+
+// DecodeImage locates a QR Code in img by its finder patterns, estimates the perspective
+// transform from finder patterns and the bottom-right alignment pattern, samples the
+// center of each module into a Matrix and then decodes it by Decode.
+// It's designed to check images rendered by writers, such as styled shapes, logos
+// and gradients, rather than scanning photos of real-world scenes.
+func DecodeImage(img image.Image) (*DecodeResult, error) {
+	b := binarize(img)
+
+	candidates := b.findFinderPatterns()
+	if len(candidates) < 3 {
+		return nil, fmt.Errorf("decode image: %w, only %d found", errFinderNotFound, len(candidates))
+	}
+
+	var lastErr error = errFinderNotFound
+	for _, finders := range chooseFinderPatterns(candidates) {
+		for _, mat := range b.sample(finders) {
+			result, err := Decode(*mat)
+			if err == nil {
+				return result, nil
+			}
+			lastErr = err
+		}
+	}
+
+	return nil, fmt.Errorf("decode image: %w", lastErr)
+}
+
+// binaryImage is a binarized image, dark modules are true.
+type binaryImage struct {
+	width, height int
+	pixels        []bool
+}
+
+// binarize converts img into binaryImage by Otsu's threshold of luminance, transparent
+// pixels are composed over white background.
+func binarize(img image.Image) *binaryImage {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	luminance := make([]uint8, w*h)
+	var histogram [256]int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// colors are alpha-premultiplied, so that missing alpha is white.
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			l := (299*r+587*g+114*b)/1000 + (0xffff - a)
+			luminance[y*w+x] = uint8(min(int(l), 0xffff) >> 8)
+			histogram[luminance[y*w+x]]++
+		}
+	}
+
+	threshold := otsuThreshold(histogram, w*h)
+	b := &binaryImage{width: w, height: h, pixels: make([]bool, w*h)}
+	for i, l := range luminance {
+		b.pixels[i] = l <= threshold
+	}
+
+	return b
+}
+
+// otsuThreshold chooses the threshold which maximizes the variance between dark and light pixels.
+func otsuThreshold(histogram [256]int, total int) uint8 {
+	sum := 0
+	for i, n := range histogram {
+		sum += i * n
+	}
+
+	var (
+		best         uint8
+		bestVariance float64
+		sumDark      int
+		numDark      int
+	)
+	for i, n := range histogram {
+		numDark += n
+		if numDark == 0 {
+			continue
+		}
+		numLight := total - numDark
+		if numLight == 0 {
+			break
+		}
+		sumDark += i * n
+
+		meanDark := float64(sumDark) / float64(numDark)
+		meanLight := float64(sum-sumDark) / float64(numLight)
+		if variance := float64(numDark) * float64(numLight) * (meanDark - meanLight) * (meanDark - meanLight); variance > bestVariance {
+			best, bestVariance = uint8(i), variance
+		}
+	}
+
+	return best
+}
+
+// dark reports whether the pixel at (x, y) is dark, pixels out of image are light.
+func (b *binaryImage) dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return false
+	}
+
+	return b.pixels[y*b.width+x]
+}
+
+// finderPattern is a candidate of finder pattern center in image.
+type finderPattern struct {
+	x, y       float64
+	moduleSize float64
+	count      int // the number of times this candidate is found.
+}
+
+// finderRatio reports whether counts of dark, light, dark, light, dark runs are
+// in the ratio 1:1:3:1:1 of finder pattern.
+func finderRatio(counts [5]int) bool {
+	total := 0
+	for _, c := range counts {
+		if c == 0 {
+			return false
+		}
+		total += c
+	}
+	if total < 7 {
+		return false
+	}
+
+	moduleSize := float64(total) / 7
+	variance := moduleSize / 2
+	return math.Abs(moduleSize-float64(counts[0])) < variance &&
+		math.Abs(moduleSize-float64(counts[1])) < variance &&
+		math.Abs(3*moduleSize-float64(counts[2])) < 3*variance &&
+		math.Abs(moduleSize-float64(counts[3])) < variance &&
+		math.Abs(moduleSize-float64(counts[4])) < variance
+}
+
+// findFinderPatterns scans each row for runs in 1:1:3:1:1, and cross checks them
+// vertically and horizontally, candidates found in several rows are merged.
+func (b *binaryImage) findFinderPatterns() []*finderPattern {
+	var candidates []*finderPattern
+
+	for y := 0; y < b.height; y++ {
+		// run length encoding of this row, starts and lengths.
+		starts, lengths := make([]int, 0, 64), make([]int, 0, 64)
+		for x := 0; x < b.width; x++ {
+			if x == 0 || b.dark(x, y) != b.dark(x-1, y) {
+				starts, lengths = append(starts, x), append(lengths, 0)
+			}
+			lengths[len(lengths)-1]++
+		}
+
+		for k := 0; k+4 < len(lengths); k++ {
+			if !b.dark(starts[k], y) {
+				continue
+			}
+			counts := [5]int{lengths[k], lengths[k+1], lengths[k+2], lengths[k+3], lengths[k+4]}
+			if !finderRatio(counts) {
+				continue
+			}
+
+			cx := float64(starts[k+2]) + float64(lengths[k+2])/2
+			if fp := b.crossCheckFinder(cx, float64(y)+0.5, counts); fp != nil {
+				candidates = mergeFinderPattern(candidates, fp)
+			}
+		}
+	}
+
+	return candidates
+}
+
+// crossCheckFinder checks finder pattern vertically and horizontally at (cx, cy), and
+// returns the refined center of finder pattern.
+func (b *binaryImage) crossCheckFinder(cx, cy float64, rowCounts [5]int) *finderPattern {
+	maxCount := rowCounts[2]
+
+	vCounts, y, ok := b.crossCheck(int(cx), int(cy), 0, 1, maxCount)
+	if !ok {
+		return nil
+	}
+	hCounts, x, ok := b.crossCheck(int(cx), int(y), 1, 0, maxCount)
+	if !ok {
+		return nil
+	}
+
+	total := 0
+	for i := range vCounts {
+		total += vCounts[i] + hCounts[i]
+	}
+
+	return &finderPattern{x: x, y: y, moduleSize: float64(total) / 14, count: 1}
+}
+
+// crossCheck counts dark, light, dark, light, dark runs centered at (x, y) along
+// (dx, dy), and returns the center of the middle dark run along the direction.
+func (b *binaryImage) crossCheck(x, y, dx, dy, maxCount int) (counts [5]int, center float64, ok bool) {
+	if !b.dark(x, y) {
+		return counts, 0, false
+	}
+
+	// backward from center.
+	p := 0
+	for ; b.dark(x+p*dx, y+p*dy); p-- {
+		counts[2]++
+	}
+	for ; !b.dark(x+p*dx, y+p*dy) && counts[1] <= maxCount && b.inside(x+p*dx, y+p*dy); p-- {
+		counts[1]++
+	}
+	for ; b.dark(x+p*dx, y+p*dy) && counts[0] <= maxCount; p-- {
+		counts[0]++
+	}
+
+	// forward from center.
+	p = 1
+	for ; b.dark(x+p*dx, y+p*dy); p++ {
+		counts[2]++
+	}
+	for ; !b.dark(x+p*dx, y+p*dy) && counts[3] <= maxCount && b.inside(x+p*dx, y+p*dy); p++ {
+		counts[3]++
+	}
+	for ; b.dark(x+p*dx, y+p*dy) && counts[4] <= maxCount; p++ {
+		counts[4]++
+	}
+
+	if !finderRatio(counts) {
+		return counts, 0, false
+	}
+
+	start := x*dx + y*dy + p - counts[4] - counts[3] - counts[2]
+	return counts, float64(start) + float64(counts[2])/2, true
+}
+
+func (b *binaryImage) inside(x, y int) bool {
+	return x >= 0 && y >= 0 && x < b.width && y < b.height
+}
+
+// mergeFinderPattern merges fp into the candidate close to it, or appends it as a new one.
+func mergeFinderPattern(candidates []*finderPattern, fp *finderPattern) []*finderPattern {
+	for _, c := range candidates {
+		if math.Abs(c.x-fp.x) <= c.moduleSize && math.Abs(c.y-fp.y) <= c.moduleSize &&
+			math.Abs(c.moduleSize-fp.moduleSize) <= math.Max(1, c.moduleSize/2) {
+			n := float64(c.count)
+			c.x = (c.x*n + fp.x) / (n + 1)
+			c.y = (c.y*n + fp.y) / (n + 1)
+			c.moduleSize = (c.moduleSize*n + fp.moduleSize) / (n + 1)
+			c.count++
+			return candidates
+		}
+	}
+
+	return append(candidates, fp)
+}
+
+// chooseFinderPatterns returns triples of finder patterns in order of top-left,
+// top-right and bottom-left, the more likely triple comes first. Candidates are
+// preferred if they are found more times, and triples are preferred if they form
+// a right isosceles triangle with similar module sizes.
+func chooseFinderPatterns(candidates []*finderPattern) [][3]*finderPattern {
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].count > candidates[j].count })
+	if len(candidates) > 10 {
+		candidates = candidates[:10]
+	}
+
+	type triple struct {
+		finders [3]*finderPattern
+		score   float64
+	}
+
+	var triples []triple
+	for i := 0; i < len(candidates); i++ {
+		for j := i + 1; j < len(candidates); j++ {
+			for k := j + 1; k < len(candidates); k++ {
+				finders, score, ok := orderFinderPatterns(candidates[i], candidates[j], candidates[k])
+				if ok {
+					triples = append(triples, triple{finders: finders, score: score})
+				}
+			}
+		}
+	}
+	sort.SliceStable(triples, func(i, j int) bool { return triples[i].score < triples[j].score })
+
+	out := make([][3]*finderPattern, len(triples))
+	for i := range triples {
+		out[i] = triples[i].finders
+	}
+
+	return out
+}
+
+// orderFinderPatterns orders a, b and c into top-left, top-right and bottom-left,
+// score is the deviation from a right isosceles triangle, the lower the better.
+func orderFinderPatterns(a, b, c *finderPattern) (finders [3]*finderPattern, score float64, ok bool) {
+	minSize := math.Min(a.moduleSize, math.Min(b.moduleSize, c.moduleSize))
+	maxSize := math.Max(a.moduleSize, math.Max(b.moduleSize, c.moduleSize))
+	if maxSize > minSize*1.5 {
+		return finders, 0, false
+	}
+
+	dist := func(p, q *finderPattern) float64 { return math.Hypot(p.x-q.x, p.y-q.y) }
+
+	// top-left is the one opposite to the longest side.
+	topLeft, p, q := a, b, c
+	if ab, ac, bc := dist(a, b), dist(a, c), dist(b, c); ab > bc && ab > ac {
+		topLeft, p, q = c, a, b
+	} else if ac > bc && ac > ab {
+		topLeft, p, q = b, a, c
+	}
+
+	// top-right is on the clockwise side in image coordinates (y axis is downward).
+	if (p.x-topLeft.x)*(q.y-topLeft.y)-(p.y-topLeft.y)*(q.x-topLeft.x) < 0 {
+		p, q = q, p
+	}
+
+	legP, legQ, hypotenuse := dist(topLeft, p), dist(topLeft, q), dist(p, q)
+	if legP < 7*minSize || legQ < 7*minSize {
+		return finders, 0, false
+	}
+	legDiff := math.Abs(legP-legQ) / math.Max(legP, legQ)
+	hypDiff := math.Abs(hypotenuse-math.Hypot(legP, legQ)) / hypotenuse
+	if legDiff > 0.2 || hypDiff > 0.1 {
+		return finders, 0, false
+	}
+
+	return [3]*finderPattern{topLeft, p, q}, legDiff + hypDiff, true
+}
+
+// sample samples modules of the symbol located by finders into matrices, in
+// different versions close to the estimated one, and with or without the
+// bottom-right alignment pattern.
+func (b *binaryImage) sample(finders [3]*finderPattern) []*Matrix {
+	topLeft, topRight, bottomLeft := finders[0], finders[1], finders[2]
+	moduleSize := (topLeft.moduleSize + topRight.moduleSize + bottomLeft.moduleSize) / 3
+
+	// distance between finder pattern centers is (dimension - 7) modules.
+	modules := (math.Hypot(topRight.x-topLeft.x, topRight.y-topLeft.y) +
+		math.Hypot(bottomLeft.x-topLeft.x, bottomLeft.y-topLeft.y)) / 2 / moduleSize
+	estimated := int(math.Round((modules + 7 - 17) / 4))
+
+	mats := make([]*Matrix, 0, 6)
+	for _, ver := range []int{estimated, estimated + 1, estimated - 1} {
+		if ver < 1 || ver > _VERSION_COUNT {
+			continue
+		}
+
+		dimension := ver*4 + 17
+		src := [][2]float64{{3.5, 3.5}, {float64(dimension) - 3.5, 3.5}, {3.5, float64(dimension) - 3.5}}
+		dst := [][2]float64{{topLeft.x, topLeft.y}, {topRight.x, topRight.y}, {bottomLeft.x, bottomLeft.y}}
+
+		// bottom-right corner of parallelogram without alignment pattern.
+		corner := [2]float64{topRight.x + bottomLeft.x - topLeft.x, topRight.y + bottomLeft.y - topLeft.y}
+		parallelogram := solvePerspective(
+			append(src, [2]float64{float64(dimension) - 3.5, float64(dimension) - 3.5}),
+			append(dst, corner),
+		)
+
+		if ver >= 2 {
+			if align, ok := b.findAlignment(parallelogram, dimension, moduleSize); ok {
+				transform := solvePerspective(
+					append(src, [2]float64{float64(dimension) - 6.5, float64(dimension) - 6.5}),
+					append(dst, align),
+				)
+				mats = append(mats, b.sampleGrid(transform, dimension))
+			}
+		}
+		mats = append(mats, b.sampleGrid(parallelogram, dimension))
+	}
+
+	return mats
+}
+
+// findAlignment searches the bottom-right alignment pattern around where it's
+// estimated by transform, and returns its center in image.
+func (b *binaryImage) findAlignment(transform perspective, dimension int, moduleSize float64) ([2]float64, bool) {
+	center := float64(dimension) - 6.5
+	ex, ey := transform.apply(center+1, center)
+	fx, fy := transform.apply(center, center+1)
+	cx, cy := transform.apply(center, center)
+	// vectors of one module along x and y axis of symbol.
+	ex, ey, fx, fy = ex-cx, ey-cy, fx-cx, fy-cy
+
+	var (
+		best         [2]float64
+		bestScore    = -1
+		bestDistance = math.MaxFloat64
+		radius       = int(math.Ceil(moduleSize * 4))
+	)
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			px, py := cx+float64(dx), cy+float64(dy)
+
+			// dark center, light ring and dark ring.
+			score := 0
+			for j := -2; j <= 2; j++ {
+				for i := -2; i <= 2; i++ {
+					want := max(abs(i), abs(j)) != 1
+					if b.dark(int(px+float64(i)*ex+float64(j)*fx), int(py+float64(i)*ey+float64(j)*fy)) == want {
+						score++
+					}
+				}
+			}
+
+			distance := float64(dx*dx + dy*dy)
+			if score > bestScore || score == bestScore && distance < bestDistance {
+				best, bestScore, bestDistance = [2]float64{px, py}, score, distance
+			}
+		}
+	}
+
+	// allow one module to be mismatched.
+	return best, bestScore >= 24
+}
+
+// sampleGrid samples the center of each module mapped by transform.
+func (b *binaryImage) sampleGrid(transform perspective, dimension int) *Matrix {
+	mat := newMatrix(dimension, dimension)
+	for y := 0; y < dimension; y++ {
+		for x := 0; x < dimension; x++ {
+			px, py := transform.apply(float64(x)+0.5, float64(y)+0.5)
+			value := QRValue_DATA_V0
+			if b.dark(int(math.Floor(px)), int(math.Floor(py))) {
+				value = QRValue_DATA_V1
+			}
+			_ = mat.set(x, y, value)
+		}
+	}
+
+	return mat
+}
+
+// perspective is a perspective transform from symbol coordinates (in modules) to
+// image coordinates (in pixels):
+//
+//	u = (h0*x + h1*y + h2) / (h6*x + h7*y + 1)
+//	v = (h3*x + h4*y + h5) / (h6*x + h7*y + 1)
+type perspective [8]float64
+
+func (t perspective) apply(x, y float64) (float64, float64) {
+	d := t[6]*x + t[7]*y + 1
+	return (t[0]*x + t[1]*y + t[2]) / d, (t[3]*x + t[4]*y + t[5]) / d
+}
+
+// solvePerspective solves the perspective transform which maps 4 src points
+// into dst points by Gaussian elimination.
+func solvePerspective(src, dst [][2]float64) perspective {
+	var a [8][9]float64
+	for i := 0; i < 4; i++ {
+		x, y, u, v := src[i][0], src[i][1], dst[i][0], dst[i][1]
+		a[2*i] = [9]float64{x, y, 1, 0, 0, 0, -x * u, -y * u, u}
+		a[2*i+1] = [9]float64{0, 0, 0, x, y, 1, -x * v, -y * v, v}
+	}
+
+	for col := 0; col < 8; col++ {
+		pivot := col
+		for row := col + 1; row < 8; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		if a[col][col] == 0 {
+			continue
+		}
+
+		for row := 0; row < 8; row++ {
+			if row == col {
+				continue
+			}
+			factor := a[row][col] / a[col][col]
+			for k := col; k < 9; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+		}
+	}
+
+	var t perspective
+	for i := range t {
+		if a[i][i] != 0 {
+			t[i] = a[i][8] / a[i][i]
+		}
+	}
+
+	return t
+}
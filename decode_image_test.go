@@ -0,0 +1,123 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// renderMatrix renders m into a gray image, each module takes blockSize pixels, and
+// the pixel (x, y) in image is mapped into symbol by transform if it's not nil.
+func renderMatrix(m *Matrix, blockSize, padding int, transform func(x, y float64) (float64, float64)) *image.Gray {
+	size := m.Width()*blockSize + 2*padding
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			fx, fy := float64(x)+0.5, float64(y)+0.5
+			if transform != nil {
+				fx, fy = transform(fx, fy)
+			}
+
+			img.SetGray(x, y, color.Gray{Y: 0xff})
+			mx := int(math.Floor((fx - float64(padding)) / float64(blockSize)))
+			my := int(math.Floor((fy - float64(padding)) / float64(blockSize)))
+			if v, err := m.at(mx, my); err == nil && v.qrbool() {
+				img.SetGray(x, y, color.Gray{Y: 0x20})
+			}
+		}
+	}
+
+	return img
+}
+
+func Test_DecodeImage(t *testing.T) {
+	text := "https://github.com/yeqown/go-qrcode"
+	qrc, err := NewWith(text, WithVersion(5), WithErrorCorrectionLevel(ErrorCorrectionMedium))
+	require.NoError(t, err)
+	size := float64(qrc.mat.Width()*6 + 2*24)
+
+	tests := []struct {
+		name      string
+		transform func(x, y float64) (float64, float64)
+	}{
+		{name: "upright"},
+		{
+			name:      "rotated 90 degrees",
+			transform: func(x, y float64) (float64, float64) { return y, size - x },
+		},
+		{
+			name:      "rotated 180 degrees",
+			transform: func(x, y float64) (float64, float64) { return size - x, size - y },
+		},
+		{
+			name: "perspective",
+			transform: func(x, y float64) (float64, float64) {
+				// inverse of a homography, the top edge is wider than the bottom edge.
+				x, y = x-size/2, y-size/2
+				y = y / (1 - 0.0008*y)
+				return x*(1+0.0008*y) + size/2, y + size/2
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := renderMatrix(qrc.mat, 6, 24, tt.transform)
+			got, err := DecodeImage(img)
+			require.NoError(t, err)
+			assert.Equal(t, text, got.Text)
+			assert.Equal(t, 5, got.Version)
+		})
+	}
+}
+
+func Test_DecodeImage_Versions(t *testing.T) {
+	for _, ver := range []int{1, 2, 7, 20} {
+		qrc, err := NewWith("go-qrcode", WithVersion(ver), WithErrorCorrectionLevel(ErrorCorrectionLow))
+		require.NoError(t, err)
+
+		got, err := DecodeImage(renderMatrix(qrc.mat, 4, 16, nil))
+		require.NoError(t, err, "version %d", ver)
+		assert.Equal(t, "go-qrcode", got.Text)
+		assert.Equal(t, ver, got.Version)
+	}
+}
+
+func Test_DecodeImage_Transparent(t *testing.T) {
+	qrc, err := NewWith("transparent background")
+	require.NoError(t, err)
+
+	// dark modules are opaque, light modules are transparent.
+	gray := renderMatrix(qrc.mat, 5, 20, nil)
+	img := image.NewNRGBA(gray.Bounds())
+	for i, y := range gray.Pix {
+		if y < 0x80 {
+			img.Set(i%gray.Stride, i/gray.Stride, color.NRGBA{R: 0x10, G: 0x30, B: 0x80, A: 0xff})
+		}
+	}
+
+	got, err := DecodeImage(img)
+	require.NoError(t, err)
+	assert.Equal(t, "transparent background", got.Text)
+}
+
+func Test_DecodeImage_NotFound(t *testing.T) {
+	_, err := DecodeImage(image.NewGray(image.Rect(0, 0, 100, 100)))
+	assert.ErrorIs(t, err, errFinderNotFound)
+}
+
+func Test_solvePerspective(t *testing.T) {
+	src := [][2]float64{{0, 0}, {10, 0}, {0, 10}, {10, 10}}
+	dst := [][2]float64{{5, 5}, {25, 6}, {4, 27}, {30, 30}}
+
+	transform := solvePerspective(src, dst)
+	for i := range src {
+		u, v := transform.apply(src[i][0], src[i][1])
+		assert.InDelta(t, dst[i][0], u, 1e-9)
+		assert.InDelta(t, dst[i][1], v, 1e-9)
+	}
+}
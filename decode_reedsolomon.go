@@ -0,0 +1,156 @@
+package qrcode
+
+import (
+	"errors"
+)
+
+// errTooManyErrors means the codewords of a block contain more errors than
+// its error correction codewords could correct.
+var errTooManyErrors = errors.New("too many errors to correct")
+
+// gf256Exp and gf256Log are the exponent and logarithm tables of GF(256) with
+// primitive polynomial x^8 + x^4 + x^3 + x^2 + 1 (0x11D), the same field used by
+// github.com/yeqown/reedsolomon to generate error correction codewords.
+var (
+	gf256Exp [512]byte
+	gf256Log [256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gf256Exp[i] = byte(x)
+		gf256Log[x] = byte(i)
+		if x <<= 1; x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	for i := 255; i < 512; i++ {
+		gf256Exp[i] = gf256Exp[i-255]
+	}
+}
+
+func gf256Mul(x, y byte) byte {
+	if x == 0 || y == 0 {
+		return 0
+	}
+
+	return gf256Exp[int(gf256Log[x])+int(gf256Log[y])]
+}
+
+// gf256Div returns x / y, y must not be 0.
+func gf256Div(x, y byte) byte {
+	if x == 0 {
+		return 0
+	}
+
+	return gf256Exp[int(gf256Log[x])+255-int(gf256Log[y])]
+}
+
+// gf256Pow returns α^n.
+func gf256Pow(n int) byte {
+	return gf256Exp[(n%255+255)%255]
+}
+
+// gf256Eval evaluates polynomial p at x, p[i] is the coefficient of x^i.
+func gf256Eval(p []byte, x byte) byte {
+	var y byte
+	for i := len(p) - 1; i >= 0; i-- {
+		y = gf256Mul(y, x) ^ p[i]
+	}
+
+	return y
+}
+
+// correctReedSolomon corrects codewords (data codewords followed by numEC error
+// correction codewords) in place, and returns the number of corrected codewords.
+//
+// NOTICE: reedsolomon.Decode of github.com/yeqown/reedsolomon is not implemented
+// yet, so syndromes are decoded here by Berlekamp-Massey algorithm, Chien search
+// and Forney algorithm, generator polynomial roots are α^0 to α^(numEC-1).
+func correctReedSolomon(codewords []byte, numEC int) (int, error) {
+	n := len(codewords)
+
+	// codewords[0] is the coefficient of x^(n-1).
+	syndromes := make([]byte, numEC)
+	hasError := false
+	for j := range syndromes {
+		var s byte
+		x := gf256Pow(j)
+		for _, c := range codewords {
+			s = gf256Mul(s, x) ^ c
+		}
+		syndromes[j] = s
+		hasError = hasError || s != 0
+	}
+	if !hasError {
+		return 0, nil
+	}
+
+	// Berlekamp-Massey finds error locator polynomial lambda.
+	lambda, prev := []byte{1}, []byte{1}
+	l, m, b := 0, 1, byte(1)
+	for k := 0; k < numEC; k++ {
+		d := syndromes[k]
+		for i := 1; i <= l && i < len(lambda); i++ {
+			d ^= gf256Mul(lambda[i], syndromes[k-i])
+		}
+		if d == 0 {
+			m++
+			continue
+		}
+
+		next := make([]byte, max(len(lambda), len(prev)+m))
+		copy(next, lambda)
+		coef := gf256Div(d, b)
+		for i, p := range prev {
+			next[i+m] ^= gf256Mul(coef, p)
+		}
+
+		if 2*l <= k {
+			prev, l, b, m = lambda, k+1-l, d, 1
+		} else {
+			m++
+		}
+		lambda = next
+	}
+	if 2*l > numEC {
+		return 0, errTooManyErrors
+	}
+
+	// Chien search finds error positions, lambda(α^-p) == 0 means error at x^p.
+	positions := make([]int, 0, l)
+	for p := 0; p < n; p++ {
+		if gf256Eval(lambda, gf256Pow(-p)) == 0 {
+			positions = append(positions, p)
+		}
+	}
+	if len(positions) != l {
+		return 0, errTooManyErrors
+	}
+
+	// Forney algorithm calculates error magnitudes by omega = syndromes * lambda mod x^numEC.
+	omega := make([]byte, numEC)
+	for i := range omega {
+		for j := 0; j <= i && j < len(lambda); j++ {
+			omega[i] ^= gf256Mul(lambda[j], syndromes[i-j])
+		}
+	}
+	for _, p := range positions {
+		xInv := gf256Pow(-p)
+
+		// formal derivative of lambda only keeps odd power terms.
+		var derivative byte
+		for i := 1; i < len(lambda); i += 2 {
+			derivative ^= gf256Mul(lambda[i], gf256Pow(-p*(i-1)))
+		}
+		if derivative == 0 {
+			return 0, errTooManyErrors
+		}
+
+		magnitude := gf256Mul(gf256Pow(p), gf256Div(gf256Eval(omega, xInv), derivative))
+		codewords[n-1-p] ^= magnitude
+	}
+
+	return len(positions), nil
+}
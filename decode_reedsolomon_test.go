@@ -0,0 +1,48 @@
+package qrcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yeqown/reedsolomon"
+	"github.com/yeqown/reedsolomon/binary"
+)
+
+func Test_correctReedSolomon(t *testing.T) {
+	data := binary.New()
+	data.AppendBytes([]byte("hello reed solomon")...)
+	encoded := reedsolomon.Encode(data, 10).Bytes()
+
+	tests := []struct {
+		name      string
+		positions []int
+		wantErr   bool
+	}{
+		{name: "no error"},
+		{name: "1 error in data", positions: []int{0}},
+		{name: "errors in data and ec", positions: []int{3, 7, 17, 20, 27}},
+		{name: "too many errors", positions: []int{0, 1, 2, 3, 4, 5}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codewords := append([]byte(nil), encoded...)
+			for _, pos := range tt.positions {
+				codewords[pos] ^= 0x5A
+			}
+
+			n, err := correctReedSolomon(codewords, 10)
+			if tt.wantErr {
+				// more errors than the capacity may be miscorrected into another codeword.
+				if err == nil {
+					assert.NotEqual(t, encoded, codewords)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.positions), n)
+			assert.Equal(t, encoded, codewords)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Decode(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts []EncodeOption
+	}{
+		{name: "numeric", text: "01234567890123"},
+		{name: "alphanumeric", text: "HELLO WORLD $%*+-./:"},
+		{name: "byte", text: "https://github.com/yeqown/go-qrcode", opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionMedium)}},
+		{name: "kanji", text: "茗荷", opts: []EncodeOption{WithEncodingMode(EncModeKanji)}},
+		{name: "optimized segments", text: "ABC1234567890abc", opts: []EncodeOption{WithOptimizedSegments()}},
+		{name: "version info", text: strings.Repeat("go-qrcode ", 20), opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionHighest)}},
+		{name: "multiple groups", text: strings.Repeat("0123456789", 30), opts: []EncodeOption{WithVersion(15), WithErrorCorrectionLevel(ErrorCorrectionLow)}},
+		{name: "ECI transcoding", text: "Привет, мир!", opts: []EncodeOption{WithECITranscoding(ECI_ISO8859_5)}},
+		{name: "FNC1", text: "0109501101530003" + "10A%B" + string(_GS) + "21XYZ", opts: []EncodeOption{WithFNC1FirstPosition(), WithOptimizedSegments()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qrc, err := NewWith(tt.text, tt.opts...)
+			require.NoError(t, err)
+
+			got, err := Decode(*qrc.mat)
+			require.NoError(t, err)
+			assert.Equal(t, tt.text, got.Text)
+			assert.Equal(t, qrc.v.Ver, got.Version)
+			assert.Equal(t, qrc.v.ECLevel, got.ECLevel)
+			assert.Equal(t, 0, got.CorrectedCodewords)
+			assert.Equal(t, len(qrc.segments), len(got.Segments))
+		})
+	}
+}
+
+func Test_Decode_Headers(t *testing.T) {
+	qrc, err := NewWith("Привет", WithECITranscoding(ECI_ISO8859_5))
+	require.NoError(t, err)
+	got, err := Decode(*qrc.mat)
+	require.NoError(t, err)
+	require.NotNil(t, got.ECI)
+	assert.Equal(t, ECI_ISO8859_5, *got.ECI)
+	assert.Equal(t, "\xbf\xe0\xd8\xd2\xd5\xe2", got.Segments[0].Data)
+
+	text := "HELLO WORLD 0123456789 hello world"
+	qrcs, err := NewStructuredAppend(text, 3)
+	require.NoError(t, err)
+	var joined strings.Builder
+	for idx, qrc := range qrcs {
+		got, err := Decode(*qrc.mat)
+		require.NoError(t, err)
+		require.NotNil(t, got.StructuredAppend)
+		assert.Equal(t, StructuredAppendHeader{Index: idx, Total: 3, Parity: xorBytes([]byte(text))},
+			*got.StructuredAppend)
+		joined.WriteString(got.Text)
+	}
+	assert.Equal(t, text, joined.String())
+}
+
+func Test_Decode_Damaged(t *testing.T) {
+	text := "https://github.com/yeqown/go-qrcode"
+	qrc, err := NewWith(text, WithErrorCorrectionLevel(ErrorCorrectionHighest))
+	require.NoError(t, err)
+
+	// flip a 3x3 area in the data region and a module of format info.
+	mat := qrc.mat.Copy()
+	for x := 10; x < 13; x++ {
+		for y := 10; y < 13; y++ {
+			v, _ := mat.at(x, y)
+			_ = mat.set(x, y, v.xor(QRValue_DATA_V1))
+		}
+	}
+	v, _ := mat.at(0, 8)
+	_ = mat.set(0, 8, v.xor(QRValue_DATA_V1))
+
+	got, err := Decode(*mat)
+	require.NoError(t, err)
+	assert.Equal(t, text, got.Text)
+	assert.Greater(t, got.CorrectedCodewords, 0)
+
+	// too many errors.
+	for x := 9; x < mat.Width()-9; x++ {
+		for y := 9; y < mat.Height()-9; y++ {
+			_ = mat.set(x, y, QRValue_DATA_V1)
+		}
+	}
+	_, err = Decode(*mat)
+	assert.Error(t, err)
+}
+
+func Test_Decode_Invalid(t *testing.T) {
+	_, err := Decode(*newMatrix(22, 22))
+	assert.Error(t, err)
+
+	_, err = Decode(*newMatrix(21, 21))
+	assert.Error(t, err)
+
+	qrc, err := NewMicro("12345")
+	require.NoError(t, err)
+	_, err = Decode(*qrc.mat)
+	assert.Error(t, err)
+}
@@ -0,0 +1,171 @@
+package qrcode
+
+import (
+	"fmt"
+
+	"github.com/yeqown/reedsolomon/binary"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// ECI (Extended Channel Interpretation) assignment number, which tells the
+// decoder which character set the following byte segments are in. Decoders
+// treat byte segments as ISO-8859-1 by default if no ECI is declared.
+//
+// reference:
+// - ISO/IEC 18004:2015 Section 7.4.3
+// - AIM ECI Specification
+type ECI int
+
+const (
+	ECI_CP437       ECI = 2
+	ECI_ISO8859_1   ECI = 3
+	ECI_ISO8859_2   ECI = 4
+	ECI_ISO8859_3   ECI = 5
+	ECI_ISO8859_4   ECI = 6
+	ECI_ISO8859_5   ECI = 7
+	ECI_ISO8859_6   ECI = 8
+	ECI_ISO8859_7   ECI = 9
+	ECI_ISO8859_8   ECI = 10
+	ECI_ISO8859_9   ECI = 11
+	ECI_ISO8859_10  ECI = 12
+	ECI_ISO8859_13  ECI = 15
+	ECI_ISO8859_14  ECI = 16
+	ECI_ISO8859_15  ECI = 17
+	ECI_ISO8859_16  ECI = 18
+	ECI_SHIFT_JIS   ECI = 20
+	ECI_WINDOWS1250 ECI = 21
+	ECI_WINDOWS1251 ECI = 22
+	ECI_WINDOWS1252 ECI = 23
+	ECI_WINDOWS1256 ECI = 24
+	ECI_UTF16BE     ECI = 25
+	ECI_UTF8        ECI = 26
+	ECI_BIG5        ECI = 28
+	ECI_GB18030     ECI = 29
+	ECI_EUC_KR      ECI = 30
+
+	// _ECI_MAX is the largest assignment number could be declared in 6 digits.
+	_ECI_MAX ECI = 999999
+)
+
+// eciCharsets maps ECI assignment number to the character set which
+// could be used to transcode UTF-8 text.
+var eciCharsets = map[ECI]encoding.Encoding{
+	ECI_CP437:       charmap.CodePage437,
+	ECI_ISO8859_1:   charmap.ISO8859_1,
+	ECI_ISO8859_2:   charmap.ISO8859_2,
+	ECI_ISO8859_3:   charmap.ISO8859_3,
+	ECI_ISO8859_4:   charmap.ISO8859_4,
+	ECI_ISO8859_5:   charmap.ISO8859_5,
+	ECI_ISO8859_6:   charmap.ISO8859_6,
+	ECI_ISO8859_7:   charmap.ISO8859_7,
+	ECI_ISO8859_8:   charmap.ISO8859_8,
+	ECI_ISO8859_9:   charmap.ISO8859_9,
+	ECI_ISO8859_10:  charmap.ISO8859_10,
+	ECI_ISO8859_13:  charmap.ISO8859_13,
+	ECI_ISO8859_14:  charmap.ISO8859_14,
+	ECI_ISO8859_15:  charmap.ISO8859_15,
+	ECI_ISO8859_16:  charmap.ISO8859_16,
+	ECI_SHIFT_JIS:   japanese.ShiftJIS,
+	ECI_WINDOWS1250: charmap.Windows1250,
+	ECI_WINDOWS1251: charmap.Windows1251,
+	ECI_WINDOWS1252: charmap.Windows1252,
+	ECI_WINDOWS1256: charmap.Windows1256,
+	ECI_UTF16BE:     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+	ECI_UTF8:        unicode.UTF8,
+	ECI_BIG5:        traditionalchinese.Big5,
+	ECI_GB18030:     simplifiedchinese.GB18030,
+	ECI_EUC_KR:      korean.EUCKR,
+}
+
+// eciOption contains settings of ECI.
+type eciOption struct {
+	// assignment number to declare.
+	assignment ECI
+
+	// transcode byte segments from UTF-8 into the character set of assignment.
+	transcode bool
+}
+
+// eciHeader returns the ECI mode indicator (0111) followed by designator of eci,
+// the designator takes 1, 2 or 3 bytes according to the assignment number:
+//
+//	000000 to 000127: 0bbbbbbb
+//	000128 to 016383: 10bbbbbb bbbbbbbb
+//	016384 to 999999: 110bbbbb bbbbbbbb bbbbbbbb
+func eciHeader(eci ECI) *binary.Binary {
+	b := binary.New(false, true, true, true)
+
+	switch {
+	case eci < 128:
+		b.AppendUint32(uint32(eci), 8)
+	case eci < 16384:
+		b.AppendUint32(0b10<<14|uint32(eci), 16)
+	default:
+		b.AppendUint32(0b110<<21|uint32(eci), 24)
+	}
+
+	return b
+}
+
+// validateECI checks if eci could be declared, and could be
+// transcoded into if transcode is true.
+func validateECI(eci ECI, transcode bool) error {
+	if eci < 0 || eci > _ECI_MAX {
+		return fmt.Errorf("invalid ECI assignment number: %d", eci)
+	}
+
+	if _, ok := eciCharsets[eci]; transcode && !ok {
+		return fmt.Errorf("could not transcode into ECI(%d): unsupported character set", eci)
+	}
+
+	return nil
+}
+
+// transcodeECI transcodes UTF-8 text into the character set of eci, InvalidCharacterError
+// is wrapped if any character is out of the character set.
+func transcodeECI(text string, eci ECI) (string, error) {
+	charset, ok := eciCharsets[eci]
+	if !ok {
+		return "", fmt.Errorf("could not transcode into ECI(%d): unsupported character set", eci)
+	}
+
+	s, err := charset.NewEncoder().String(text)
+	if err == nil {
+		return s, nil
+	}
+	// locate the first character which could not be transcoded.
+	for i, r := range text {
+		if _, err2 := charset.NewEncoder().String(string(r)); err2 != nil {
+			err = &InvalidCharacterError{Rune: r, Offset: i, Mode: EncModeByte}
+			break
+		}
+	}
+
+	return "", fmt.Errorf("could not transcode into ECI(%d): %w", eci, err)
+}
+
+// transcodeSegments transcodes data of byte segments into the character set of eci,
+// other segments are kept as they are. segs would not be modified.
+func transcodeSegments(segs []Segment, eci ECI) ([]Segment, error) {
+	out := make([]Segment, len(segs))
+	for i, seg := range segs {
+		out[i] = seg
+		if seg.Mode != EncModeByte {
+			continue
+		}
+
+		data, err := transcodeECI(seg.Data, eci)
+		if err != nil {
+			return nil, err
+		}
+		out[i].Data = data
+	}
+
+	return out, nil
+}
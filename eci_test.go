@@ -0,0 +1,115 @@
+package qrcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yeqown/reedsolomon/binary"
+)
+
+// bitsString returns b in string of '0' and '1'.
+func bitsString(b *binary.Binary) string {
+	bits := make([]byte, b.Len())
+	for i := range bits {
+		bits[i] = '0'
+		if b.At(i) {
+			bits[i] = '1'
+		}
+	}
+
+	return string(bits)
+}
+
+func Test_eciHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		eci  ECI
+		want string
+	}{
+		{
+			name: "UTF-8 in 1 byte",
+			eci:  ECI_UTF8,
+			want: "0111" + "00011010",
+		},
+		{
+			name: "128 in 2 bytes",
+			eci:  128,
+			want: "0111" + "10" + "00000010000000",
+		},
+		{
+			name: "999999 in 3 bytes",
+			eci:  999999,
+			want: "0111" + "110" + "011110100001000111111",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, bitsString(eciHeader(tt.eci)))
+		})
+	}
+}
+
+func Test_transcodeSegments(t *testing.T) {
+	segs := []Segment{
+		{Mode: EncModeNumeric, Data: "2026"},
+		{Mode: EncModeByte, Data: "Привет"},
+	}
+
+	got, err := transcodeSegments(segs, ECI_ISO8859_5)
+	require.NoError(t, err)
+	assert.Equal(t, "2026", got[0].Data)
+	assert.Equal(t, "\xbf\xe0\xd8\xd2\xd5\xe2", got[1].Data)
+	// segs should not be modified.
+	assert.Equal(t, "Привет", segs[1].Data)
+
+	_, err = transcodeSegments([]Segment{{Mode: EncModeByte, Data: "مرحبا"}}, ECI_ISO8859_5)
+	assert.Error(t, err)
+}
+
+func Test_NewWith_ECI(t *testing.T) {
+	text := "Привет, мир! Привет, мир!"
+
+	plain, err := NewWith(text)
+	require.NoError(t, err)
+
+	withECI, err := NewWith(text, WithUTF8ECI())
+	require.NoError(t, err)
+	assert.Equal(t, plain.segments, withECI.segments)
+	assert.Equal(t, 12, withECI.encoder.header.Len())
+
+	// Cyrillic takes 2 bytes in UTF-8, but only 1 byte in ISO-8859-5.
+	transcoded, err := NewWith(text, WithECITranscoding(ECI_ISO8859_5))
+	require.NoError(t, err)
+	assert.Equal(t, len([]rune(text)), len(transcoded.segments[0].Data))
+	assert.Less(t, transcoded.v.Ver, plain.v.Ver)
+}
+
+func Test_NewWith_ECI_Invalid(t *testing.T) {
+	_, err := NewWith("مرحبا", WithECITranscoding(ECI_SHIFT_JIS))
+	assert.Error(t, err)
+
+	_, err = NewWith("abc", WithECITranscoding(1000))
+	assert.Error(t, err)
+
+	_, err = NewWith("abc", WithECI(-1))
+	assert.Error(t, err)
+
+	// unknown character set could be declared, but not transcoded.
+	_, err = NewWith("abc", WithECI(1000))
+	assert.NoError(t, err)
+}
+
+func Test_NewWith_ECI_HeaderBits(t *testing.T) {
+	// 17 bytes fill version 1-L, the ECI header pushes them into version 2.
+	text := "abcdefghijklmnopq"
+
+	plain, err := NewWith(text, WithErrorCorrectionLevel(ErrorCorrectionLow))
+	require.NoError(t, err)
+	assert.Equal(t, 1, plain.v.Ver)
+
+	withECI, err := NewWith(text, WithErrorCorrectionLevel(ErrorCorrectionLow), WithUTF8ECI())
+	require.NoError(t, err)
+	assert.Equal(t, 2, withECI.v.Ver)
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"log"
 	"strconv"
-	"unicode/utf8"
 
 	"github.com/yeqown/reedsolomon/binary"
 	"golang.org/x/text/encoding/japanese"
@@ -99,16 +98,27 @@ type encoder struct {
 	dst *binary.Binary
 
 	// initial params
-	mode encMode // encode mode
+	mode encMode // encode mode, EncModeNone means segments are in mixed modes.
 	ecLv ecLevel // error correction level
 
 	// self load
 	version version // QR version ref
+
+	// header is written before all segments, such as ECI designator, nil means no header.
+	header *binary.Binary
+
+	// micro is the Micro QR Code version to encode in, nil means regular QR Code.
+	micro *microVersion
+
+	// rmqr is the rMQR Code version to encode in, nil means regular QR Code.
+	rmqr *rmqrVersion
 }
 
 func newEncoder(m encMode, ec ecLevel, v version) *encoder {
 	switch m {
 	case EncModeNumeric, EncModeAlphanumeric, EncModeByte, EncModeKanji:
+	case EncModeNone:
+		// mixed segments, each segment carries its own mode.
 	default:
 		panic("unsupported data encoding mode in newEncoder()")
 	}
@@ -125,32 +135,67 @@ func newEncoder(m encMode, ec ecLevel, v version) *encoder {
 // 1. encode raw data into bitset
 // 2. append _defaultPadding data
 func (e *encoder) Encode(raw string) (*binary.Binary, error) {
+	return e.EncodeSegments([]Segment{{Mode: e.mode, Data: raw}})
+}
+
+// EncodeSegments encodes segments into bitset one by one, each segment
+// with its own mode indicator and character count indicator, and then
+// append _defaultPadding data.
+func (e *encoder) EncodeSegments(segs []Segment) (*binary.Binary, error) {
 	e.dst = binary.New()
 
-	var (
-		data      []byte
-		charCount = 0 // Character count for the character count indicator
-	)
-	switch e.mode {
+	if e.header != nil {
+		e.dst.Append(e.header)
+	}
+	for _, seg := range segs {
+		e.encodeSegment(seg)
+	}
+
+	if e.micro != nil {
+		if err := e.breakUpMicro(); err != nil {
+			return nil, err
+		}
+		return e.dst, nil
+	}
+
+	// fill and _defaultPadding bits
+	if err := e.breakUpInto8bit(); err != nil {
+		return nil, err
+	}
+
+	return e.dst, nil
+}
+
+// encodeSegment appends mode indicator, character count indicator and
+// encoded data of seg into e.dst.
+func (e *encoder) encodeSegment(seg Segment) {
+	var data []byte
+	switch seg.Mode {
 	case EncModeNumeric, EncModeAlphanumeric, EncModeByte:
-		data = []byte(raw)
-		charCount = len(data)
+		data = []byte(seg.Data)
 	case EncModeKanji:
-		data = toShiftJIS(raw)
-		// For Kanji mode, charCount is the number of Kanji characters, not bytes
-		charCount = utf8.RuneCountInString(raw)
+		data = toShiftJIS(seg.Data)
 	default:
-		log.Printf("unsupported encoding mode: %s", getEncModeName(e.mode))
+		log.Printf("unsupported encoding mode: %s", getEncModeName(seg.Mode))
 	}
 
-	// append mode indicator symbol
-	indicator := getEncodeModeIndicator(e.mode)
-	e.dst.Append(indicator)
-	// append chars length counter bits symbol
-	e.dst.AppendUint32(uint32(charCount), e.charCountBits())
+	// append mode indicator symbol and chars length counter bits symbol,
+	// for Kanji mode, charCount is the number of Kanji characters, not bytes.
+	switch {
+	case e.micro != nil:
+		e.dst.AppendUint32(e.micro.modeIndicator(seg.Mode), e.micro.modeIndicatorBits())
+		e.dst.AppendUint32(uint32(seg.charCount()), e.micro.charCountBits(seg.Mode))
+	case e.rmqr != nil:
+		e.dst.AppendUint32(e.rmqr.modeIndicator(seg.Mode), rmqrModeIndicatorBits)
+		e.dst.AppendUint32(uint32(seg.charCount()), e.rmqr.charCountBits(seg.Mode))
+	default:
+		indicator := getEncodeModeIndicator(seg.Mode)
+		e.dst.Append(indicator)
+		e.dst.AppendUint32(uint32(seg.charCount()), charCountBits(e.version.Ver, seg.Mode))
+	}
 
 	// encode data with specified mode
-	switch e.mode {
+	switch seg.Mode {
 	case EncModeNumeric:
 		e.encodeNumeric(data)
 	case EncModeAlphanumeric:
@@ -160,15 +205,8 @@ func (e *encoder) Encode(raw string) (*binary.Binary, error) {
 	case EncModeByte:
 		e.encodeByte(data)
 	default:
-		log.Printf("unsupported encoding mode: %s", getEncModeName(e.mode))
-	}
-
-	// fill and _defaultPadding bits
-	if err := e.breakUpInto8bit(); err != nil {
-		return nil, err
+		log.Printf("unsupported encoding mode: %s", getEncModeName(seg.Mode))
 	}
-
-	return e.dst, nil
 }
 
 // 0001b mode indicator
@@ -305,19 +343,19 @@ func (e *encoder) encodeKanji(data []byte) {
 
 // Break Up into 8-bit Codewords and Add Pad Bytes if Necessary
 func (e *encoder) breakUpInto8bit() error {
-	// fill ending code (max 4bit)
+	// fill ending code (max 4bit, 3bit in rMQR Code)
 	// depends on max capacity of current version and EC level
+	terminator := 4
+	if e.rmqr != nil {
+		terminator = rmqrTerminatorBits
+	}
 	maxCap := e.version.NumTotalCodewords() * 8
 	if less := maxCap - e.dst.Len(); less < 0 {
-		err := fmt.Errorf(
-			"wrong version(%d) cap(%d bits) and could not contain all bits: %d bits",
-			e.version.Ver, maxCap, e.dst.Len(),
-		)
-		return err
-	} else if less < 4 {
+		return &DataTooLongError{Needed: e.dst.Len(), Max: maxCap, Version: e.version.Ver, ECLevel: e.ecLv}
+	} else if less < terminator {
 		e.dst.AppendNumBools(less, false)
 	} else {
-		e.dst.AppendNumBools(4, false)
+		e.dst.AppendNumBools(terminator, false)
 	}
 
 	// append `0` to be 8 times bits length
@@ -358,15 +396,20 @@ var charCountMap = map[string]int{
 
 // charCountBits
 func (e *encoder) charCountBits() int {
+	return charCountBits(e.version.Ver, e.mode)
+}
+
+// charCountBits returns the length of character count indicator of mode in version v.
+func charCountBits(v int, mode encMode) int {
 	var lv int
-	if v := e.version.Ver; v <= 9 {
+	if v <= 9 {
 		lv = 9
 	} else if v <= 26 {
 		lv = 26
 	} else {
 		lv = 40
 	}
-	pos := fmt.Sprintf("%d_%s", lv, getEncModeName(e.mode))
+	pos := fmt.Sprintf("%d_%s", lv, getEncModeName(mode))
 	return charCountMap[pos]
 }
 
@@ -1,5 +1,7 @@
 package qrcode
 
+import "image"
+
 type EncodeOption interface {
 	apply(option *encodingOption)
 }
@@ -20,12 +22,49 @@ type encodingOption struct {
 	// If the automatically analyzed version is lower than this, this minimum will be used.
 	MinimumVersion int
 
+	// MaximumVersion specifies the maximum version of target QR code, 0 means no limit.
+	// Building fails with DataTooLongError if the data needs a higher version.
+	MaximumVersion int
+
 	// EncMode specifies which encMode to use
 	EncMode encMode
 
 	// EcLevel specifies which ecLevel to use
 	EcLevel ecLevel
 
+	// OptimizeSegments splits input into segments of different modes with the
+	// fewest bits, only works while EncMode is EncModeAuto.
+	OptimizeSegments bool
+
+	// ECI declares the character set of byte segments, nil means no ECI header.
+	ECI *eciOption
+
+	// FNC1 enables FNC1 mode for GS1 or other industry applications, nil means no FNC1 mode indicator.
+	FNC1 *fnc1Option
+
+	// RMQRHeight and RMQRWidth specify the size of rMQR Code, 0 means any height or width.
+	RMQRHeight int
+	RMQRWidth  int
+
+	// BoostErrorCorrection raises EcLevel as high as the chosen version allows.
+	BoostErrorCorrection bool
+
+	// MaskPattern forces the mask pattern reference, nil means choosing by MaskSelector.
+	MaskPattern *int
+
+	// MaskSelector scores each mask pattern, nil means DefaultMaskSelector.
+	MaskSelector MaskSelector
+
+	// Verify decodes the final matrix and compares it with source text after building.
+	Verify bool
+
+	// QArt steers padding codewords to approximate an image, nil means standard padding.
+	QArt *qartOption
+
+	// structuredAppend is set by NewStructuredAppend for each symbol, nil means
+	// the symbol is not a part of Structured Append sequence.
+	structuredAppend *structuredAppend
+
 	// PS: The version (which implicitly defines the byte capacity of the qrcode) is dynamically selected at runtime
 }
 
@@ -86,3 +125,135 @@ func WithMinimumVersion(version int) EncodeOption {
 		option.MinimumVersion = version
 	})
 }
+
+// WithMaximumVersion sets the maximum version of target QR code, such as the largest
+// symbol which fits in a label at the required module size. Building fails with
+// DataTooLongError rather than choosing a higher version. With WithBoostErrorCorrection,
+// the highest error correction level which still fits in the maximum version is chosen.
+func WithMaximumVersion(version int) EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		if version < 1 || version > _VERSION_COUNT {
+			return
+		}
+
+		option.MaximumVersion = version
+	})
+}
+
+// WithOptimizedSegments splits the input data into numeric, alphanumeric, byte and kanji
+// segments which takes the fewest bits (ISO/IEC 18004 Annex J), rather than encoding
+// the whole input in a single mode. It only works with EncModeAuto, since an explicit
+// encoding mode is specified by WithEncodingMode.
+func WithOptimizedSegments() EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.OptimizeSegments = true
+	})
+}
+
+// WithECI declares the character set of byte segments by emitting an ECI
+// (Extended Channel Interpretation) header before segments, such as ECI_UTF8.
+// Data is written as it is, use WithECITranscoding to transcode it into the character set.
+func WithECI(eci ECI) EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.ECI = &eciOption{assignment: eci}
+	})
+}
+
+// WithUTF8ECI declares byte segments are UTF-8 encoded (ECI 26), so that scanners
+// would not decode them as ISO-8859-1 which is the default character set.
+func WithUTF8ECI() EncodeOption {
+	return WithECI(ECI_UTF8)
+}
+
+// WithECITranscoding transcodes byte segments from UTF-8 into the character set of eci,
+// such as ECI_ISO8859_5 or ECI_SHIFT_JIS, and declares it by an ECI header.
+// Encoding fails if the input contains any character which is not in the character set.
+func WithECITranscoding(eci ECI) EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.ECI = &eciOption{assignment: eci, transcode: true}
+	})
+}
+
+// WithFNC1FirstPosition enables FNC1 in first position mode, which marks the data
+// as GS1 element strings. Group Separator (0x1D) in the input stands for FNC1 used
+// as field separator, it's encoded as '%' in alphanumeric mode.
+func WithFNC1FirstPosition() EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.FNC1 = &fnc1Option{}
+	})
+}
+
+// WithFNC1SecondPosition enables FNC1 in second position mode, which marks the data
+// is formatted according to an industry application specified by AIM. appIndicator
+// is the Application Indicator assigned by AIM, a 2 digits number or a letter.
+func WithFNC1SecondPosition(appIndicator string) EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.FNC1 = &fnc1Option{secondPosition: true, appIndicator: appIndicator}
+	})
+}
+
+// WithRMQRSize specifies the size R{height}x{width} of rMQR Code generated by NewRMQR,
+// such as WithRMQRSize(7, 0) for any rMQR Code with 7 modules height, 0 means
+// any height or width. NewRMQR fails if no such size could contain the data.
+func WithRMQRSize(height, width int) EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.RMQRHeight = height
+		option.RMQRWidth = width
+	})
+}
+
+// WithVerify decodes the final matrix after masking and compares the payload with
+// the source text, so that building fails with ErrVerifyFailed rather than producing
+// an unreadable symbol. It's only available for regular QR Code since Decode reads
+// regular QR Code only, NewMicro and NewRMQR fail if it's specified.
+func WithVerify() EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.Verify = true
+	})
+}
+
+// WithBoostErrorCorrection raises the error correction level as high as the chosen
+// version could still contain the data, so spare capacity is spent on error
+// recovery without enlarging the symbol. The level specified by
+// WithErrorCorrectionLevel is the minimum one.
+func WithBoostErrorCorrection() EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.BoostErrorCorrection = true
+	})
+}
+
+// WithMaskPattern forces the mask pattern reference (0-7) rather than choosing the one
+// with the lowest penalty, to reproduce symbols generated by other system bit for bit.
+// Micro QR Code has only 4 mask patterns (0-3), and rMQR Code has a fixed one.
+// Building fails if pattern is out of range.
+func WithMaskPattern(pattern int) EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.MaskPattern = &pattern
+	})
+}
+
+// WithMaskSelector replaces DefaultMaskSelector to score mask patterns, such as
+// adding penalty for dark modules which would be covered by a logo.
+func WithMaskSelector(selector MaskSelector) EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.MaskSelector = selector
+	})
+}
+
+// WithQArt steers padding codewords in the style of QArt, so that data and error
+// correction modules approximate the target image while the symbol still decodes
+// to the same text. Only codewords after the terminator could be steered, so a
+// larger version (WithVersion) and a lower error correction level leave more room
+// for the image. Modules with brighter pixels in priority are matched first, and
+// nil priority means the contrast of target is used. The mask pattern which matches
+// target best is chosen unless WithMaskPattern is specified, so building fails if
+// WithMaskSelector is specified too. It's only available for regular QR Code.
+func WithQArt(target, priority image.Image) EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		if target == nil {
+			return
+		}
+
+		option.QArt = &qartOption{target: target, priority: priority}
+	})
+}
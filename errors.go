@@ -0,0 +1,74 @@
+package qrcode
+
+import "fmt"
+
+// DataTooLongError means data could not be contained in the symbol, it's returned
+// while data overflows the version specified by WithVersion, or any version if
+// version is chosen automatically.
+type DataTooLongError struct {
+	// Needed the number of data bits needed by headers and segments, -1 means the
+	// length of a segment overflows its character count indicator.
+	Needed int
+
+	// Max the number of data bits of Version.
+	Max int
+
+	// Version the version which could not contain data, it's the largest
+	// version (40) if version is chosen automatically. It's 1-4 for M1-M4 of
+	// Micro QR Code, and 0 for rMQR Code which has sizes rather than versions.
+	Version int
+
+	// ECLevel error correction level.
+	ECLevel ecLevel
+
+	// symbol the name of Micro QR Code or rMQR Code symbol, such as "M4" or "R17x139",
+	// empty means regular QR Code.
+	symbol string
+
+	// err is the error wrapped, errAnalyzeVersionFailed if version is chosen automatically.
+	err error
+}
+
+func (e *DataTooLongError) Error() string {
+	symbol := e.symbol
+	if symbol == "" {
+		symbol = fmt.Sprintf("version %d", e.Version)
+	}
+	msg := fmt.Sprintf("data too long: need %d bits, but %s-%s could contain %d bits",
+		e.Needed, symbol, ecLevelName(e.ECLevel), e.Max)
+	if e.err != nil {
+		msg = e.err.Error() + ", " + msg
+	}
+
+	return msg
+}
+
+func (e *DataTooLongError) Unwrap() error {
+	return e.err
+}
+
+// InvalidCharacterError means a character of data could not be encoded in the mode.
+type InvalidCharacterError struct {
+	// Rune the character could not be encoded.
+	Rune rune
+
+	// Offset the byte offset of Rune in data.
+	Offset int
+
+	// Mode the encoding mode.
+	Mode encMode
+}
+
+func (e *InvalidCharacterError) Error() string {
+	return fmt.Sprintf("character '%c' (U+%04X) cannot be encoded in %s mode at offset %d",
+		e.Rune, e.Rune, getEncModeName(e.Mode), e.Offset)
+}
+
+// ecLevelName returns the letter of error correction level, such as "L" for ErrorCorrectionLow.
+func ecLevelName(ec ecLevel) string {
+	if ec < ErrorCorrectionLow || ec > ErrorCorrectionHighest {
+		return "?"
+	}
+
+	return string("LMQH"[ec-ErrorCorrectionLow])
+}
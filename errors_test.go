@@ -0,0 +1,174 @@
+package qrcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DataTooLongError(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func() error
+		want DataTooLongError
+	}{
+		{
+			name: "any version",
+			fn: func() error {
+				_, err := NewWith(strings.Repeat("a", 3000), WithErrorCorrectionLevel(ErrorCorrectionLow))
+				return err
+			},
+			want: DataTooLongError{Needed: 4 + 16 + 3000*8, Max: 2956 * 8, Version: 40, ECLevel: ErrorCorrectionLow},
+		},
+		{
+			name: "specified version",
+			fn: func() error {
+				_, err := NewWith("0123456789", WithVersion(1), WithErrorCorrectionLevel(ErrorCorrectionHighest),
+					WithEncodingMode(EncModeByte))
+				return err
+			},
+			want: DataTooLongError{Needed: 4 + 8 + 10*8, Max: 9 * 8, Version: 1, ECLevel: ErrorCorrectionHighest},
+		},
+		{
+			name: "segments",
+			fn: func() error {
+				_, err := NewWithSegments([]Segment{
+					{Mode: EncModeByte, Data: strings.Repeat("a", 1000)},
+					{Mode: EncModeByte, Data: strings.Repeat("b", 1000)},
+				}, WithErrorCorrectionLevel(ErrorCorrectionHighest))
+				return err
+			},
+			want: DataTooLongError{Needed: 2 * (4 + 16 + 1000*8), Max: 1276 * 8, Version: 40, ECLevel: ErrorCorrectionHighest},
+		},
+		{
+			name: "estimate",
+			fn: func() error {
+				_, err := Estimate(strings.Repeat("a", 20), WithVersion(1), WithErrorCorrectionLevel(ErrorCorrectionMedium))
+				return err
+			},
+			want: DataTooLongError{Needed: 4 + 8 + 20*8, Max: 16 * 8, Version: 1, ECLevel: ErrorCorrectionMedium},
+		},
+		{
+			name: "maximum version",
+			fn: func() error {
+				_, err := NewWith(strings.Repeat("a", 300), WithMaximumVersion(5),
+					WithErrorCorrectionLevel(ErrorCorrectionMedium))
+				return err
+			},
+			// character count indicator of byte mode is 8 bits in version 5 which overflows,
+			// bits are counted in the analyzed version whose indicator is 16 bits.
+			want: DataTooLongError{Needed: 4 + 16 + 300*8, Max: 86 * 8, Version: 5, ECLevel: ErrorCorrectionMedium},
+		},
+		{
+			name: "micro",
+			fn: func() error {
+				_, err := NewMicro(strings.Repeat("1", 50), WithErrorCorrectionLevel(ErrorCorrectionMedium))
+				return err
+			},
+			// M4: 3 bits mode indicator and 6 bits character count indicator.
+			want: DataTooLongError{Needed: 3 + 6 + 16*10 + 7, Max: 14 * 8, Version: 4, ECLevel: ErrorCorrectionMedium},
+		},
+		{
+			name: "rMQR",
+			fn: func() error {
+				_, err := NewRMQR(strings.Repeat("a", 1000), WithErrorCorrectionLevel(ErrorCorrectionMedium))
+				return err
+			},
+			// the character count indicator of R17x139 overflows.
+			want: DataTooLongError{Needed: -1, Max: 152 * 8, Version: 0, ECLevel: ErrorCorrectionMedium},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fn()
+			var target *DataTooLongError
+			require.True(t, errors.As(err, &target), "got %v", err)
+			assert.Equal(t, tt.want.Needed, target.Needed)
+			assert.Equal(t, tt.want.Max, target.Max)
+			assert.Equal(t, tt.want.Version, target.Version)
+			assert.Equal(t, tt.want.ECLevel, target.ECLevel)
+			if tt.want.Needed >= 0 {
+				assert.Greater(t, target.Needed, target.Max)
+			}
+		})
+	}
+}
+
+func Test_DataTooLongError_Encoder(t *testing.T) {
+	e := newEncoder(EncModeByte, ErrorCorrectionLow, loadVersion(1, ErrorCorrectionLow))
+	_, err := e.Encode(strings.Repeat("a", 20))
+
+	var target *DataTooLongError
+	require.True(t, errors.As(err, &target))
+	assert.Equal(t, 4+8+20*8, target.Needed)
+	assert.Equal(t, 19*8, target.Max)
+	assert.Equal(t, "data too long: need 172 bits, but version 1-L could contain 152 bits", err.Error())
+
+	_, err = NewMicro("123456", WithVersion(1), WithErrorCorrectionLevel(ErrorCorrectionLow))
+	require.True(t, errors.As(err, &target))
+	assert.Contains(t, err.Error(), "but M1-L could contain 20 bits")
+
+	e = newEncoder(EncModeNumeric, ErrorCorrectionLow, version{})
+	e.micro = &microVersions[0]
+	_, err = e.EncodeSegments([]Segment{{Mode: EncModeNumeric, Data: "123456"}})
+	require.True(t, errors.As(err, &target))
+	assert.Equal(t, 3+20, target.Needed)
+	assert.Equal(t, "data too long: need 23 bits, but M1-L could contain 20 bits", err.Error())
+}
+
+func Test_InvalidCharacterError(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func() error
+		want InvalidCharacterError
+	}{
+		{
+			name: "numeric",
+			fn: func() error {
+				_, err := NewWith("123a5", WithEncodingMode(EncModeNumeric))
+				return err
+			},
+			want: InvalidCharacterError{Rune: 'a', Offset: 3, Mode: EncModeNumeric},
+		},
+		{
+			name: "alphanumeric offset in bytes",
+			fn: func() error {
+				_, err := NewWithSegments([]Segment{
+					{Mode: EncModeByte, Data: "中"},
+					{Mode: EncModeAlphanumeric, Data: "AB中"},
+				})
+				return err
+			},
+			want: InvalidCharacterError{Rune: '中', Offset: 2, Mode: EncModeAlphanumeric},
+		},
+		{
+			name: "kanji",
+			fn: func() error {
+				_, err := Estimate("茗荷a", WithEncodingMode(EncModeKanji))
+				return err
+			},
+			want: InvalidCharacterError{Rune: 'a', Offset: 6, Mode: EncModeKanji},
+		},
+		{
+			name: "ECI transcoding",
+			fn: func() error {
+				_, err := NewWith("Hi, Привет", WithECITranscoding(ECI_ISO8859_1))
+				return err
+			},
+			want: InvalidCharacterError{Rune: 'П', Offset: 4, Mode: EncModeByte},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fn()
+			var target *InvalidCharacterError
+			require.True(t, errors.As(err, &target), "got %v", err)
+			assert.Equal(t, tt.want, *target)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package qrcode
+
+// Estimation is the result of Estimate, which tells the version that text would
+// get and how many data bits it takes.
+type Estimation struct {
+	// Version code 1-40, 0 means no version could contain the text.
+	Version int
+
+	// ECLevel error correction level.
+	ECLevel ecLevel
+
+	// DataBits the number of bits used by headers and segments.
+	DataBits int
+
+	// CapacityBits the number of data bits of Version.
+	CapacityBits int
+}
+
+// Estimate chooses version and counts data bits of text with the same options as
+// NewWith, but it neither allocates matrix nor encodes data and applies masks, so
+// it's cheap enough to validate input on every keystroke. An error is returned if
+// text could not be encoded or doesn't fit in any version (or the version specified
+// by WithVersion), Version, DataBits and CapacityBits are still set in the latter case.
+func Estimate[T ~string | ~[]byte](text T, opts ...EncodeOption) (Estimation, error) {
+	dst := DefaultEncodingOption()
+	for _, opt := range opts {
+		opt.apply(dst)
+	}
+
+	q := &QRCode{
+		sourceText:     string(toBytes(text)),
+		encodingOption: dst,
+	}
+	err := q.prepare()
+
+	est := Estimation{ECLevel: dst.EcLevel}
+	if q.v.Ver > 0 {
+		est.Version = q.v.Ver
+		est.DataBits = q.dataBits()
+		est.CapacityBits = q.v.NumTotalCodewords() * 8
+	}
+
+	return est, err
+}
@@ -0,0 +1,63 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Estimate(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts []EncodeOption
+	}{
+		{name: "numeric", text: "0123456789"},
+		{name: "byte in H", text: "https://github.com/yeqown/go-qrcode", opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionHighest)}},
+		{name: "optimized segments", text: "0123456789012345ABCDEFabc", opts: []EncodeOption{WithOptimizedSegments()}},
+		{name: "eci", text: "Привет", opts: []EncodeOption{WithECITranscoding(ECI_ISO8859_5)}},
+		{name: "minimum version", text: "abc", opts: []EncodeOption{WithMinimumVersion(5)}},
+		{name: "large", text: strings.Repeat("estimate", 200)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			est, err := Estimate(tt.text, tt.opts...)
+			require.NoError(t, err)
+
+			// the same as building the symbol.
+			qrc, err := NewWith(tt.text, tt.opts...)
+			require.NoError(t, err)
+			info := qrc.Info()
+			assert.Equal(t, Estimation{
+				Version:      info.Version,
+				ECLevel:      info.ECLevel,
+				DataBits:     info.DataBits,
+				CapacityBits: info.CapacityBits,
+			}, est)
+		})
+	}
+}
+
+func Test_Estimate_TooLong(t *testing.T) {
+	est, err := Estimate(strings.Repeat("a", 100), WithVersion(2), WithErrorCorrectionLevel(ErrorCorrectionLow))
+	assert.Error(t, err)
+	assert.Equal(t, 2, est.Version)
+	assert.Greater(t, est.DataBits, est.CapacityBits)
+
+	est, err = Estimate(strings.Repeat("a", 3000))
+	assert.Error(t, err)
+	assert.Equal(t, 0, est.Version)
+
+	_, err = Estimate("abc", WithEncodingMode(EncModeNumeric))
+	assert.Error(t, err)
+}
+
+func Benchmark_Estimate(b *testing.B) {
+	text := "https://github.com/yeqown/go-qrcode?utm_source=estimate"
+	for i := 0; i < b.N; i++ {
+		_, _ = Estimate(text, WithOptimizedSegments())
+	}
+}
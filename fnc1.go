@@ -0,0 +1,69 @@
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yeqown/reedsolomon/binary"
+)
+
+// _GS is the ASCII Group Separator which stands for FNC1 used as field
+// separator, such as the end of a variable length GS1 element string.
+const _GS = '\x1d'
+
+// fnc1Option contains settings of FNC1 mode.
+type fnc1Option struct {
+	// secondPosition indicates FNC1 in second position mode, otherwise FNC1 in first position.
+	secondPosition bool
+
+	// appIndicator is the Application Indicator of FNC1 in second position mode,
+	// a 2 digits number or a letter.
+	appIndicator string
+}
+
+// header returns FNC1 mode indicator, 0101 for first position, 1001 for
+// second position followed by Application Indicator in 8 bits.
+// appIndicator should be validated by parseAppIndicator in advance.
+func (f fnc1Option) header() *binary.Binary {
+	if !f.secondPosition {
+		return binary.New(false, true, false, true)
+	}
+
+	value, _ := parseAppIndicator(f.appIndicator)
+	b := binary.New(true, false, false, true)
+	b.AppendUint32(uint32(value), 8)
+
+	return b
+}
+
+// parseAppIndicator converts Application Indicator into its value in FNC1
+// second position mode, indicator must be a 2 digits number or a letter.
+func parseAppIndicator(indicator string) (int, error) {
+	switch {
+	case len(indicator) == 2 && analyzeNum(rune(indicator[0])) && analyzeNum(rune(indicator[1])):
+		return int(indicator[0]-'0')*10 + int(indicator[1]-'0'), nil
+	case len(indicator) == 1 &&
+		(indicator[0] >= 'a' && indicator[0] <= 'z' || indicator[0] >= 'A' && indicator[0] <= 'Z'):
+		return int(indicator[0]) + 100, nil
+	}
+
+	return 0, fmt.Errorf("invalid FNC1 application indicator: %q", indicator)
+}
+
+// fnc1AlphanumericEscaper escapes text into alphanumeric mode in FNC1 mode,
+// '%' stands for FNC1 (Group Separator), so literal '%' should be doubled.
+var fnc1AlphanumericEscaper = strings.NewReplacer("%", "%%", string(_GS), "%")
+
+// escapeFNC1Segments escapes data of alphanumeric segments in FNC1 mode,
+// other segments are kept as they are. segs would not be modified.
+func escapeFNC1Segments(segs []Segment) []Segment {
+	out := make([]Segment, len(segs))
+	for i, seg := range segs {
+		out[i] = seg
+		if seg.Mode == EncModeAlphanumeric {
+			out[i].Data = fnc1AlphanumericEscaper.Replace(seg.Data)
+		}
+	}
+
+	return out
+}
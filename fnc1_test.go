@@ -0,0 +1,85 @@
+package qrcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fnc1Option_header(t *testing.T) {
+	assert.Equal(t, "0101", bitsString(fnc1Option{}.header()))
+	// digits are encoded as the number.
+	assert.Equal(t, "1001"+"00100101", bitsString(fnc1Option{secondPosition: true, appIndicator: "37"}.header()))
+	// letters are encoded as ASCII value plus 100.
+	assert.Equal(t, "1001"+"10100101", bitsString(fnc1Option{secondPosition: true, appIndicator: "A"}.header()))
+}
+
+func Test_parseAppIndicator(t *testing.T) {
+	tests := []struct {
+		indicator string
+		want      int
+		wantErr   bool
+	}{
+		{indicator: "00", want: 0},
+		{indicator: "99", want: 99},
+		{indicator: "a", want: 197},
+		{indicator: "Z", want: 190},
+		{indicator: "", wantErr: true},
+		{indicator: "1", wantErr: true},
+		{indicator: "100", wantErr: true},
+		{indicator: "%", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.indicator, func(t *testing.T) {
+			got, err := parseAppIndicator(tt.indicator)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_escapeFNC1Segments(t *testing.T) {
+	segs := []Segment{
+		{Mode: EncModeAlphanumeric, Data: "10ABC%\x1d21"},
+		{Mode: EncModeByte, Data: "ab%\x1d"},
+	}
+
+	got := escapeFNC1Segments(segs)
+	assert.Equal(t, "10ABC%%%21", got[0].Data)
+	assert.Equal(t, "ab%\x1d", got[1].Data)
+	// segs should not be modified.
+	assert.Equal(t, "10ABC%\x1d21", segs[0].Data)
+}
+
+func Test_optimizeSegments_FNC1(t *testing.T) {
+	raw := "0109501101530003" + "10ABC123\x1d" + "21XYZ"
+
+	// Group Separator is not alphanumeric without FNC1.
+	for _, seg := range optimizeSegments(raw, 1, false) {
+		if seg.Mode == EncModeAlphanumeric {
+			assert.NotContains(t, seg.Data, "\x1d")
+		}
+	}
+
+	got := optimizeSegments(raw, 1, true)
+	assert.Equal(t, []Segment{
+		{Mode: EncModeNumeric, Data: "010950110153000310"},
+		{Mode: EncModeAlphanumeric, Data: "ABC123\x1d21XYZ"},
+	}, got)
+}
+
+func Test_NewWith_FNC1(t *testing.T) {
+	qrc, err := NewWith("ABC%123", WithFNC1SecondPosition("37"), WithEncodingMode(EncModeAlphanumeric))
+	require.NoError(t, err)
+	assert.Equal(t, []Segment{{Mode: EncModeAlphanumeric, Data: "ABC%%123"}}, qrc.segments)
+	assert.Equal(t, "1001"+"00100101", bitsString(qrc.encoder.header))
+
+	_, err = NewWith("ABC", WithFNC1SecondPosition("ABC"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,278 @@
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GS1Element is a GS1 element string, an Application Identifier (AI) followed by its data.
+//
+// reference:
+// - GS1 General Specifications Section 3 and Section 7.8
+type GS1Element struct {
+	AI   string
+	Data string
+}
+
+// gs1AIFormat describes the data format of an Application Identifier.
+type gs1AIFormat struct {
+	minLen  int
+	maxLen  int
+	numeric bool // data must be digits only, otherwise characters in GS1 AI encodable character set 82.
+	check   bool // the last digit of data is a GS1 check digit.
+	date    bool // data is a date in YYMMDD.
+}
+
+var (
+	gs1N6Date = gs1AIFormat{minLen: 6, maxLen: 6, numeric: true, date: true}
+	gs1N6     = gs1AIFormat{minLen: 6, maxLen: 6, numeric: true}
+	gs1X20    = gs1AIFormat{minLen: 1, maxLen: 20}
+	gs1X30    = gs1AIFormat{minLen: 1, maxLen: 30}
+	gs1N13Chk = gs1AIFormat{minLen: 13, maxLen: 13, numeric: true, check: true}
+)
+
+// gs1AIs contains the commonly used Application Identifiers, families with the
+// last digit as decimal point indicator (such as 310n) are filled by init.
+var gs1AIs = map[string]gs1AIFormat{
+	"00":   {minLen: 18, maxLen: 18, numeric: true, check: true}, // SSCC
+	"01":   {minLen: 14, maxLen: 14, numeric: true, check: true}, // GTIN
+	"02":   {minLen: 14, maxLen: 14, numeric: true, check: true}, // CONTENT
+	"10":   gs1X20,                                               // BATCH/LOT
+	"11":   gs1N6Date,                                            // PROD DATE
+	"12":   gs1N6Date,                                            // DUE DATE
+	"13":   gs1N6Date,                                            // PACK DATE
+	"15":   gs1N6Date,                                            // BEST BEFORE
+	"16":   gs1N6Date,                                            // SELL BY
+	"17":   gs1N6Date,                                            // USE BY / EXPIRY
+	"20":   {minLen: 2, maxLen: 2, numeric: true},                // VARIANT
+	"21":   gs1X20,                                               // SERIAL
+	"22":   gs1X20,                                               // CPV
+	"235":  {minLen: 1, maxLen: 28},                              // TPX
+	"240":  gs1X30,                                               // ADDITIONAL ID
+	"241":  gs1X30,                                               // CUST. PART No.
+	"242":  {minLen: 1, maxLen: 6, numeric: true},                // MTO VARIANT
+	"243":  gs1X20,                                               // PCN
+	"250":  gs1X30,                                               // SECONDARY SERIAL
+	"251":  gs1X30,                                               // REF. TO SOURCE
+	"253":  {minLen: 13, maxLen: 30},                             // GDTI
+	"254":  gs1X20,                                               // GLN EXTENSION COMPONENT
+	"255":  {minLen: 13, maxLen: 25, numeric: true},              // GCN
+	"30":   {minLen: 1, maxLen: 8, numeric: true},                // VAR. COUNT
+	"37":   {minLen: 1, maxLen: 8, numeric: true},                // COUNT
+	"400":  gs1X30,                                               // ORDER NUMBER
+	"401":  gs1X30,                                               // GINC
+	"402":  {minLen: 17, maxLen: 17, numeric: true, check: true}, // GSIN
+	"403":  gs1X30,                                               // ROUTE
+	"410":  gs1N13Chk,                                            // SHIP TO LOC
+	"411":  gs1N13Chk,                                            // BILL TO
+	"412":  gs1N13Chk,                                            // PURCHASE FROM
+	"413":  gs1N13Chk,                                            // SHIP FOR LOC
+	"414":  gs1N13Chk,                                            // LOC No.
+	"415":  gs1N13Chk,                                            // PAY TO
+	"416":  gs1N13Chk,                                            // PROD/SERV LOC
+	"417":  gs1N13Chk,                                            // PARTY
+	"420":  gs1X20,                                               // SHIP TO POST
+	"421":  {minLen: 4, maxLen: 12},                              // SHIP TO POST
+	"422":  {minLen: 3, maxLen: 3, numeric: true},                // ORIGIN
+	"7003": {minLen: 10, maxLen: 10, numeric: true},              // EXPIRY TIME
+	"8003": {minLen: 14, maxLen: 30},                             // GRAI
+	"8004": gs1X30,                                               // GIAI
+	"8005": gs1N6,                                                // PRICE PER UNIT
+	"8006": {minLen: 18, maxLen: 18, numeric: true},              // ITIP
+	"8017": {minLen: 18, maxLen: 18, numeric: true, check: true}, // GSRN - PROVIDER
+	"8018": {minLen: 18, maxLen: 18, numeric: true, check: true}, // GSRN - RECIPIENT
+	"8020": {minLen: 1, maxLen: 25},                              // REF No.
+	"8200": {minLen: 1, maxLen: 70},                              // PRODUCT URL
+	"90":   gs1X30,                                               // INTERNAL
+}
+
+func init() {
+	// measures and amounts, the last digit indicates the position of decimal point.
+	for _, family := range []string{
+		"310", "311", "312", "313", "314", "315", "316",
+		"320", "321", "322", "323", "324", "325", "326", "327", "328", "329",
+		"330", "331", "332", "333", "334", "335", "336", "337",
+		"340", "341", "342", "343", "344", "345", "346", "347", "348", "349",
+		"350", "351", "352", "353", "354", "355", "356", "357",
+		"360", "361", "362", "363", "364", "365", "366", "367", "368", "369",
+	} {
+		for d := '0'; d <= '9'; d++ {
+			gs1AIs[family+string(d)] = gs1N6
+		}
+	}
+	for d := '0'; d <= '9'; d++ {
+		gs1AIs["390"+string(d)] = gs1AIFormat{minLen: 1, maxLen: 15, numeric: true} // AMOUNT
+		gs1AIs["391"+string(d)] = gs1AIFormat{minLen: 4, maxLen: 18, numeric: true} // AMOUNT with ISO currency
+		gs1AIs["392"+string(d)] = gs1AIFormat{minLen: 1, maxLen: 15, numeric: true} // PRICE
+		gs1AIs["393"+string(d)] = gs1AIFormat{minLen: 4, maxLen: 18, numeric: true} // PRICE with ISO currency
+	}
+	for d := '1'; d <= '9'; d++ {
+		gs1AIs["9"+string(d)] = gs1AIFormat{minLen: 1, maxLen: 90} // COMPANY INTERNAL
+	}
+}
+
+// gs1PredefinedLengths contains the first 2 digits of AIs whose element strings are
+// predefined length, they never need FNC1 as separator even if they are not the last one.
+var gs1PredefinedLengths = map[string]bool{
+	"00": true, "01": true, "02": true, "03": true, "04": true,
+	"11": true, "12": true, "13": true, "14": true, "15": true, "16": true, "17": true,
+	"18": true, "19": true, "20": true,
+	"31": true, "32": true, "33": true, "34": true, "35": true, "36": true,
+	"41": true,
+}
+
+// ParseGS1 parses GS1 element strings in human readable form, such as
+// "(01)09501101530003(17)261231(10)ABC", and validates each element by the
+// data format of its AI, including length, character set and check digit.
+func ParseGS1(s string) ([]GS1Element, error) {
+	if s == "" {
+		return nil, fmt.Errorf("gs1: empty element string")
+	}
+
+	elems := make([]GS1Element, 0, 4)
+	for s != "" {
+		if s[0] != '(' {
+			return nil, fmt.Errorf("gs1: expect '(' at %q", s)
+		}
+		end := strings.IndexByte(s, ')')
+		if end < 0 {
+			return nil, fmt.Errorf("gs1: missing ')' in %q", s)
+		}
+
+		ai := s[1:end]
+		s = s[end+1:]
+		next := gs1NextAI(s)
+
+		elem := GS1Element{AI: ai, Data: s[:next]}
+		if err := elem.Validate(); err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+		s = s[next:]
+	}
+
+	return elems, nil
+}
+
+// gs1NextAI returns the index of the next "(AI)" in s, or len(s) if there is none.
+// '(' and ')' are valid characters in data, so only '(' followed by a known AI and
+// ')' starts the next element.
+func gs1NextAI(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '(' {
+			continue
+		}
+		if end := strings.IndexByte(s[i:], ')'); end > 0 {
+			if _, ok := gs1AIs[s[i+1:i+end]]; ok {
+				return i
+			}
+		}
+	}
+
+	return len(s)
+}
+
+// Validate checks the data of element by the data format of its AI.
+func (e GS1Element) Validate() error {
+	format, ok := gs1AIs[e.AI]
+	if !ok {
+		return fmt.Errorf("gs1: unknown AI (%s)", e.AI)
+	}
+
+	if n := len(e.Data); n < format.minLen || n > format.maxLen {
+		if format.minLen == format.maxLen {
+			return fmt.Errorf("gs1: AI (%s) requires %d characters, got %d", e.AI, format.minLen, n)
+		}
+		return fmt.Errorf("gs1: AI (%s) requires %d to %d characters, got %d",
+			e.AI, format.minLen, format.maxLen, n)
+	}
+
+	for i := 0; i < len(e.Data); i++ {
+		c := rune(e.Data[i])
+		if format.numeric && !analyzeNum(c) || !format.numeric && !analyzeGS1Char(c) {
+			return fmt.Errorf("gs1: AI (%s) invalid character %q at %d", e.AI, c, i)
+		}
+	}
+
+	if format.check && gs1CheckDigit(e.Data[:len(e.Data)-1]) != e.Data[len(e.Data)-1] {
+		return fmt.Errorf("gs1: AI (%s) invalid check digit of %s", e.AI, e.Data)
+	}
+
+	if format.date {
+		year := int(e.Data[0]-'0')*10 + int(e.Data[1]-'0')
+		month := int(e.Data[2]-'0')*10 + int(e.Data[3]-'0')
+		day := int(e.Data[4]-'0')*10 + int(e.Data[5]-'0')
+		// day 00 means the last day of month, and YY is taken as 20YY for leap years.
+		if month < 1 || month > 12 || day > daysInMonth(2000+year, month) {
+			return fmt.Errorf("gs1: AI (%s) invalid date %s", e.AI, e.Data)
+		}
+	}
+
+	return nil
+}
+
+// daysInMonth returns the number of days in month of year.
+func daysInMonth(year, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// analyzeGS1Char is r in GS1 AI encodable character set 82.
+func analyzeGS1Char(r rune) bool {
+	switch {
+	case r >= '0' && r <= '9', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		return true
+	}
+
+	return strings.ContainsRune("!\"%&'()*+,-./:;<=>?_", r)
+}
+
+// gs1CheckDigit calculates GS1 check digit of digits by modulo 10, weights
+// 3 and 1 are applied alternately from the rightmost digit.
+func gs1CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+// GS1ElementString concatenates elements into the data to encode in FNC1 first
+// position mode, Group Separator (0x1D) is inserted after each element which
+// is not predefined length except the last one.
+func GS1ElementString(elems []GS1Element) string {
+	var sb strings.Builder
+	for i, elem := range elems {
+		sb.WriteString(elem.AI)
+		sb.WriteString(elem.Data)
+		if i < len(elems)-1 && !gs1PredefinedLengths[elem.AI[:2]] {
+			sb.WriteByte(_GS)
+		}
+	}
+
+	return sb.String()
+}
+
+// NewGS1 generate a GS1 QRCode from GS1 element strings in human readable form,
+// such as "(01)09501101530003(17)261231(10)ABC". Elements are validated, and
+// encoded in FNC1 first position mode with optimized segments.
+func NewGS1(elementString string, opts ...EncodeOption) (*QRCode, error) {
+	elems, err := ParseGS1(elementString)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := DefaultEncodingOption()
+	for _, opt := range opts {
+		opt.apply(dst)
+	}
+	dst.FNC1 = &fnc1Option{}
+	dst.OptimizeSegments = true
+
+	return build([]byte(GS1ElementString(elems)), nil, dst)
+}
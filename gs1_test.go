@@ -0,0 +1,111 @@
+package qrcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseGS1(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []GS1Element
+		wantErr bool
+	}{
+		{
+			name: "GTIN, expiry and batch",
+			s:    "(01)09501101530003(17)261231(10)ABC",
+			want: []GS1Element{
+				{AI: "01", Data: "09501101530003"},
+				{AI: "17", Data: "261231"},
+				{AI: "10", Data: "ABC"},
+			},
+		},
+		{
+			name: "SSCC and net weight",
+			s:    "(00)106141411234567897(3103)000525",
+			want: []GS1Element{
+				{AI: "00", Data: "106141411234567897"},
+				{AI: "3103", Data: "000525"},
+			},
+		},
+		{
+			name: "parentheses in data",
+			s:    "(10)AB(C(21)(X)Y)(17)261231",
+			want: []GS1Element{
+				{AI: "10", Data: "AB(C"},
+				{AI: "21", Data: "(X)Y)"},
+				{AI: "17", Data: "261231"},
+			},
+		},
+		{
+			name: "day 00 and leap day",
+			s:    "(17)260200(15)280229",
+			want: []GS1Element{
+				{AI: "17", Data: "260200"},
+				{AI: "15", Data: "280229"},
+			},
+		},
+		{name: "empty", s: "", wantErr: true},
+		{name: "missing AI", s: "0109501101530003", wantErr: true},
+		{name: "missing ')'", s: "(0109501101530003", wantErr: true},
+		{name: "unknown AI", s: "(999)123", wantErr: true},
+		{name: "GTIN too short", s: "(01)0950110153000", wantErr: true},
+		{name: "GTIN invalid check digit", s: "(01)09501101530004", wantErr: true},
+		{name: "GTIN not numeric", s: "(01)0950110153000A", wantErr: true},
+		{name: "batch too long", s: "(10)ABCDEFGHIJKLMNOPQRSTU", wantErr: true},
+		{name: "batch invalid character", s: "(10)AB C", wantErr: true},
+		{name: "empty data", s: "(10)(01)09501101530003", wantErr: true},
+		{name: "invalid month", s: "(17)261301", wantErr: true},
+		{name: "invalid day of month", s: "(17)260231", wantErr: true},
+		{name: "invalid leap day", s: "(17)270229", wantErr: true},
+		{name: "day 31 of 30 days month", s: "(17)260431", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGS1(tt.s)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_gs1CheckDigit(t *testing.T) {
+	assert.Equal(t, byte('3'), gs1CheckDigit("0950110153000"))
+	assert.Equal(t, byte('7'), gs1CheckDigit("10614141123456789"))
+	assert.Equal(t, byte('0'), gs1CheckDigit("0000000000000"))
+}
+
+func Test_GS1ElementString(t *testing.T) {
+	elems := []GS1Element{
+		{AI: "10", Data: "ABC"},
+		{AI: "01", Data: "09501101530003"},
+		{AI: "21", Data: "12345"},
+		{AI: "17", Data: "261231"},
+	}
+
+	// only the variable length element (10) which is not the last one needs separator.
+	assert.Equal(t, "10ABC\x1d"+"0109501101530003"+"2112345\x1d"+"17261231", GS1ElementString(elems))
+}
+
+func Test_NewGS1(t *testing.T) {
+	qrc, err := NewGS1("(01)09501101530003(17)261231(10)ABC%(21)12345")
+	require.NoError(t, err)
+	assert.Equal(t, "0109501101530003"+"17261231"+"10ABC%\x1d"+"2112345", qrc.sourceText)
+	assert.Equal(t, "0101", bitsString(qrc.encoder.header))
+	assert.Equal(t, []Segment{
+		{Mode: EncModeNumeric, Data: "0109501101530003" + "1726123110"},
+		{Mode: EncModeAlphanumeric, Data: "ABC%%%"},
+		{Mode: EncModeNumeric, Data: "2112345"},
+	}, qrc.segments)
+
+	_, err = NewGS1("(01)09501101530004")
+	assert.Error(t, err)
+}
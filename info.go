@@ -0,0 +1,188 @@
+package qrcode
+
+import "fmt"
+
+// SymbolInfo describes a built symbol, such as the version, how many data bits
+// are used and how many characters could still be added.
+type SymbolInfo struct {
+	// Name of the symbol size, such as "7" for QR Code version 7,
+	// "M3" for Micro QR Code M3 and "R13x43" for rMQR Code R13x43.
+	Name string
+
+	// Version code 1-40 for QR Code, 1-4 for Micro QR Code (M1-M4),
+	// and 0 for rMQR Code which is identified by Width and Height.
+	Version int
+
+	// Width and Height of symbol in modules, quiet zone is not included.
+	Width, Height int
+
+	// ECLevel error correction level.
+	ECLevel ecLevel
+
+	// MaskPattern the mask pattern reference applied to symbol.
+	MaskPattern int
+
+	// Segments of the data bit stream in order.
+	Segments []SegmentInfo
+
+	// DataBits the number of bits used by headers (ECI, FNC1 and Structured Append)
+	// and segments, terminator and padding are not included.
+	DataBits int
+
+	// CapacityBits the number of data bits the symbol could contain.
+	CapacityBits int
+
+	// Remaining the number of characters of each mode which could still be
+	// added to symbol as a new segment.
+	Remaining CharCapacity
+
+	// Groups block structure of data and error correction codewords.
+	Groups []BlockGroup
+}
+
+// CharCapacity the number of characters of each mode.
+type CharCapacity struct {
+	Numeric      int
+	Alphanumeric int
+	Byte         int
+	Kanji        int
+}
+
+// SegmentInfo describes an encoded segment.
+type SegmentInfo struct {
+	// Mode of segment.
+	Mode encMode
+
+	// Length the value of character count indicator, which is the number
+	// of bytes for byte mode and characters for other modes.
+	Length int
+
+	// Bits the number of bits used by segment, including mode indicator
+	// and character count indicator.
+	Bits int
+}
+
+// BlockGroup describes a group of blocks which have the same number of codewords.
+type BlockGroup struct {
+	// NumBlocks the number of blocks in group.
+	NumBlocks int
+
+	// NumDataCodewords the number of data codewords in each block.
+	NumDataCodewords int
+
+	// NumECCodewords the number of error correction codewords in each block.
+	NumECCodewords int
+}
+
+// Info returns the information of built symbol.
+func (q *QRCode) Info() SymbolInfo {
+	info := SymbolInfo{
+		Width:       q.mat.Width(),
+		Height:      q.mat.Height(),
+		ECLevel:     q.encodingOption.EcLevel,
+		MaskPattern: q.maskPattern,
+	}
+
+	// overhead returns the length of mode indicator and character count indicator of
+	// mode, ok is false if mode is not available.
+	var overhead func(mode encMode) (modeBits, ccBits int, ok bool)
+
+	switch {
+	case q.micro != nil:
+		info.Name = fmt.Sprintf("M%d", q.micro.Ver)
+		info.Version = q.micro.Ver
+		info.ECLevel = q.micro.ECLevel
+		info.CapacityBits = q.micro.DataBits
+		info.Groups = []BlockGroup{{
+			NumBlocks:        1,
+			NumDataCodewords: q.micro.NumDataCodewords(),
+			NumECCodewords:   q.micro.NumECCodewords,
+		}}
+		overhead = func(mode encMode) (int, int, bool) {
+			ccBits := q.micro.charCountBits(mode)
+			return q.micro.modeIndicatorBits(), ccBits, ccBits > 0
+		}
+	case q.rmqr != nil:
+		info.Name = fmt.Sprintf("R%dx%d", q.rmqr.Height, q.rmqr.Width)
+		info.CapacityBits = q.v.NumTotalCodewords() * 8
+		overhead = func(mode encMode) (int, int, bool) {
+			return rmqrModeIndicatorBits, q.rmqr.charCountBits(mode), true
+		}
+	default:
+		info.Name = fmt.Sprintf("%d", q.v.Ver)
+		info.Version = q.v.Ver
+		info.CapacityBits = q.v.NumTotalCodewords() * 8
+		info.DataBits = q.header().Len()
+		overhead = func(mode encMode) (int, int, bool) {
+			return 4, charCountBits(q.v.Ver, mode), true
+		}
+	}
+
+	if q.micro == nil {
+		for _, g := range q.v.Groups {
+			info.Groups = append(info.Groups, BlockGroup{
+				NumBlocks:        g.NumBlocks,
+				NumDataCodewords: g.NumDataCodewords,
+				NumECCodewords:   g.ECBlockwordsPerBlock,
+			})
+		}
+	}
+
+	for _, seg := range q.segments {
+		modeBits, ccBits, _ := overhead(seg.Mode)
+		si := SegmentInfo{Mode: seg.Mode, Length: seg.charCount(), Bits: modeBits + ccBits + seg.dataBitLen()}
+		info.Segments = append(info.Segments, si)
+		info.DataBits += si.Bits
+	}
+
+	// remaining returns the number of characters of mode in a new segment.
+	free := info.CapacityBits - info.DataBits
+	remaining := func(mode encMode) int {
+		modeBits, ccBits, ok := overhead(mode)
+		if !ok {
+			return 0
+		}
+
+		return min(charsInBits(mode, free-modeBits-ccBits), 1<<ccBits-1)
+	}
+	info.Remaining = CharCapacity{
+		Numeric:      remaining(EncModeNumeric),
+		Alphanumeric: remaining(EncModeAlphanumeric),
+		Byte:         remaining(EncModeByte),
+		Kanji:        remaining(EncModeKanji),
+	}
+
+	return info
+}
+
+// charsInBits returns the maximum number of characters of mode could be encoded
+// in bits, it's the inverse of Segment.dataBitLen.
+func charsInBits(mode encMode, bits int) int {
+	if bits <= 0 {
+		return 0
+	}
+
+	switch mode {
+	case EncModeNumeric:
+		n := bits / 10 * 3
+		switch rest := bits % 10; {
+		case rest >= 7:
+			n += 2
+		case rest >= 4:
+			n++
+		}
+		return n
+	case EncModeAlphanumeric:
+		n := bits / 11 * 2
+		if bits%11 >= 6 {
+			n++
+		}
+		return n
+	case EncModeByte:
+		return bits / 8
+	case EncModeKanji:
+		return bits / 13
+	}
+
+	return 0
+}
@@ -0,0 +1,115 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_QRCode_Info(t *testing.T) {
+	qrc, err := NewWith("HELLO WORLD", WithVersion(1), WithErrorCorrectionLevel(ErrorCorrectionQuart), WithMaskPattern(3))
+	require.NoError(t, err)
+
+	info := qrc.Info()
+	assert.Equal(t, SymbolInfo{
+		Name:         "1",
+		Version:      1,
+		Width:        21,
+		Height:       21,
+		ECLevel:      ErrorCorrectionQuart,
+		MaskPattern:  3,
+		Segments:     []SegmentInfo{{Mode: EncModeAlphanumeric, Length: 11, Bits: 74}},
+		DataBits:     74,
+		CapacityBits: 104,
+		Remaining:    CharCapacity{Numeric: 4, Alphanumeric: 3, Byte: 2, Kanji: 1},
+		Groups:       []BlockGroup{{NumBlocks: 1, NumDataCodewords: 13, NumECCodewords: 13}},
+	}, info)
+}
+
+func Test_QRCode_Info_Headers(t *testing.T) {
+	qrc, err := NewWith("0123456789abc", WithOptimizedSegments(), WithUTF8ECI(), WithErrorCorrectionLevel(ErrorCorrectionHighest))
+	require.NoError(t, err)
+
+	info := qrc.Info()
+	require.Len(t, info.Segments, 2)
+	assert.Equal(t, EncModeNumeric, info.Segments[0].Mode)
+	assert.Equal(t, EncModeByte, info.Segments[1].Mode)
+	// ECI header takes 12 bits.
+	assert.Equal(t, 12+info.Segments[0].Bits+info.Segments[1].Bits, info.DataBits)
+	assert.Equal(t, qrc.dataBits(), info.DataBits)
+
+	qrc, err = NewWith(strings.Repeat("groups", 50), WithErrorCorrectionLevel(ErrorCorrectionHighest))
+	require.NoError(t, err)
+	info = qrc.Info()
+	require.Len(t, info.Groups, 2)
+	assert.Equal(t, info.Groups[0].NumDataCodewords+1, info.Groups[1].NumDataCodewords)
+}
+
+// Test_QRCode_Info_Remaining checks that remaining characters could be added
+// as a new segment in the same version, but one more could not.
+func Test_QRCode_Info_Remaining(t *testing.T) {
+	seg := Segment{Mode: EncModeByte, Data: "https://github.com/yeqown"}
+	qrc, err := NewWithSegments([]Segment{seg})
+	require.NoError(t, err)
+	info := qrc.Info()
+
+	chars := map[encMode]string{
+		EncModeNumeric:      "1",
+		EncModeAlphanumeric: "A",
+		EncModeByte:         "a",
+		EncModeKanji:        "茗",
+	}
+	remaining := map[encMode]int{
+		EncModeNumeric:      info.Remaining.Numeric,
+		EncModeAlphanumeric: info.Remaining.Alphanumeric,
+		EncModeByte:         info.Remaining.Byte,
+		EncModeKanji:        info.Remaining.Kanji,
+	}
+	for mode, n := range remaining {
+		opts := []EncodeOption{WithVersion(info.Version), WithErrorCorrectionLevel(info.ECLevel)}
+		_, err = NewWithSegments([]Segment{seg, {Mode: mode, Data: strings.Repeat(chars[mode], n)}}, opts...)
+		assert.NoError(t, err, getEncModeName(mode))
+		_, err = NewWithSegments([]Segment{seg, {Mode: mode, Data: strings.Repeat(chars[mode], n+1)}}, opts...)
+		assert.Error(t, err, getEncModeName(mode))
+	}
+}
+
+func Test_QRCode_Info_MicroAndRMQR(t *testing.T) {
+	qrc, err := NewMicro("12345", WithVersion(1), WithErrorCorrectionLevel(ErrorCorrectionLow))
+	require.NoError(t, err)
+	info := qrc.Info()
+	assert.Equal(t, "M1", info.Name)
+	assert.Equal(t, 1, info.Version)
+	assert.Equal(t, 11, info.Width)
+	assert.Equal(t, 20, info.CapacityBits)
+	// 3 bits character count indicator, no mode indicator.
+	assert.Equal(t, []SegmentInfo{{Mode: EncModeNumeric, Length: 5, Bits: 3 + 17}}, info.Segments)
+	assert.Equal(t, CharCapacity{}, info.Remaining)
+	assert.Equal(t, []BlockGroup{{NumBlocks: 1, NumDataCodewords: 3, NumECCodewords: 2}}, info.Groups)
+
+	qrc, err = NewRMQR("0123456789", WithRMQRSize(11, 27), WithErrorCorrectionLevel(ErrorCorrectionMedium))
+	require.NoError(t, err)
+	info = qrc.Info()
+	assert.Equal(t, "R11x27", info.Name)
+	assert.Equal(t, 27, info.Width)
+	assert.Equal(t, 11, info.Height)
+	assert.Equal(t, qrc.rmqr.Groups[0].NumDataCodewords*8, info.CapacityBits)
+	assert.Equal(t, 3+qrc.rmqr.CharCountBits[0]+34, info.DataBits)
+}
+
+func Test_charsInBits(t *testing.T) {
+	segs := []Segment{
+		{Mode: EncModeNumeric, Data: "0123456789"},
+		{Mode: EncModeAlphanumeric, Data: "ABCDEFGHIJ"},
+		{Mode: EncModeByte, Data: "abcdefghij"},
+		{Mode: EncModeKanji, Data: "茗荷茗荷茗荷"},
+	}
+	for _, seg := range segs {
+		n := seg.charCount()
+		assert.Equal(t, n, charsInBits(seg.Mode, seg.dataBitLen()), getEncModeName(seg.Mode))
+		assert.Equal(t, n-1, charsInBits(seg.Mode, seg.dataBitLen()-1), getEncModeName(seg.Mode))
+	}
+	assert.Equal(t, 0, charsInBits(EncModeByte, -8))
+}
@@ -136,3 +136,33 @@ func modulo6Func(x, y int) bool {
 func modulo7Func(x, y int) bool {
 	return ((x+y)%2+(x*y)%3)%2 == 0
 }
+
+// MaskSelector scores a masked matrix to choose the mask pattern, the pattern with
+// the lowest penalty is applied. Penalty is called concurrently by each pattern,
+// so it must be safe for concurrent use.
+type MaskSelector interface {
+	// Penalty returns the penalty of mat which is masked by pattern, 0-7 for regular
+	// QR Code and 0-3 for Micro QR Code, format and version info are filled already.
+	Penalty(mat Matrix, pattern int) int
+}
+
+// MaskSelectorFunc is an adapter to use ordinary function as MaskSelector.
+type MaskSelectorFunc func(mat Matrix, pattern int) int
+
+// Penalty calls f(mat, pattern).
+func (f MaskSelectorFunc) Penalty(mat Matrix, pattern int) int {
+	return f(mat, pattern)
+}
+
+// DefaultMaskSelector scores masked matrix by the four penalty rules of ISO/IEC 18004,
+// custom MaskSelector could add its own penalty to EvaluateMask.
+var DefaultMaskSelector MaskSelector = MaskSelectorFunc(func(mat Matrix, _ int) int {
+	return EvaluateMask(mat)
+})
+
+// EvaluateMask calculates the penalty of masked matrix by the four rules of ISO/IEC 18004,
+// which are adjacent modules in row/column in same color, block of modules in same color,
+// finder-like pattern in row/column and proportion of dark modules.
+func EvaluateMask(mat Matrix) int {
+	return evaluation(&mat)
+}
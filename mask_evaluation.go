@@ -169,3 +169,32 @@ func rule4(mat *Matrix) int {
 
 	return min(previous, next) / 5 * 10
 }
+
+// evaluationMicro calculates a score after masking Micro QR Code matrix, the
+// higher the better. It counts dark modules in the right and bottom edges
+// (excluding timing patterns), SUM1 and SUM2:
+//
+//	score = SUM1 * 16 + SUM2 (SUM1 <= SUM2)
+//	score = SUM2 * 16 + SUM1 (SUM1 > SUM2)
+//
+// reference:
+// - ISO/IEC 18004:2015 Section 7.8.3.2
+func evaluationMicro(mat *Matrix) int {
+	sum1, sum2 := 0, 0
+	for i := 1; i < mat.Height(); i++ {
+		if v, _ := mat.at(mat.Width()-1, i); v.qrbool() {
+			sum1++
+		}
+	}
+	for i := 1; i < mat.Width(); i++ {
+		if v, _ := mat.at(i, mat.Height()-1); v.qrbool() {
+			sum2++
+		}
+	}
+
+	if sum1 <= sum2 {
+		return sum1*16 + sum2
+	}
+
+	return sum2*16 + sum1
+}
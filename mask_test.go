@@ -1,8 +1,10 @@
 package qrcode
 
 import (
+	"math"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -50,3 +52,87 @@ func TestMask(t *testing.T) {
 	mask7 := newMask(cpyMat, modulo7)
 	_ = debugDraw("./testdata/modulo7.jpeg", *mask7.mat)
 }
+
+func Test_WithMaskPattern(t *testing.T) {
+	text := "https://github.com/yeqown/go-qrcode"
+	for pattern := 0; pattern < 8; pattern++ {
+		qrc, err := NewWith(text, WithMaskPattern(pattern))
+		require.NoError(t, err)
+		assert.Equal(t, pattern, qrc.maskPattern)
+
+		result, err := Decode(*qrc.mat)
+		require.NoError(t, err)
+		assert.Equal(t, pattern, result.MaskPattern)
+		assert.Equal(t, text, result.Text)
+	}
+
+	// out of range pattern is rejected rather than choosing another one.
+	for _, pattern := range []int{-1, 8, 9} {
+		_, err := NewWith(text, WithMaskPattern(pattern))
+		assert.Error(t, err, pattern)
+		_, err = Estimate(text, WithMaskPattern(pattern))
+		assert.Error(t, err, pattern)
+	}
+}
+
+func Test_WithMaskSelector(t *testing.T) {
+	text := "https://github.com/yeqown/go-qrcode"
+
+	// DefaultMaskSelector is used if no selector is specified.
+	auto, err := NewWith(text)
+	require.NoError(t, err)
+	qrc, err := NewWith(text, WithMaskSelector(DefaultMaskSelector))
+	require.NoError(t, err)
+	assert.Equal(t, auto.maskPattern, qrc.maskPattern)
+	assert.Equal(t, auto.mat.Bitmap(), qrc.mat.Bitmap())
+
+	// the pattern with the lowest penalty is chosen by default.
+	lowest, best := math.MaxInt32, -1
+	for pattern := 0; pattern < 8; pattern++ {
+		masked, err := NewWith(text, WithMaskPattern(pattern))
+		require.NoError(t, err)
+		if score := EvaluateMask(*masked.mat); score < lowest {
+			lowest, best = score, pattern
+		}
+	}
+	assert.Equal(t, best, auto.maskPattern)
+
+	// custom selector favours pattern 5.
+	qrc, err = NewWith(text, WithMaskSelector(MaskSelectorFunc(func(mat Matrix, pattern int) int {
+		if pattern == 5 {
+			return 0
+		}
+		return EvaluateMask(mat)
+	})))
+	require.NoError(t, err)
+	result, err := Decode(*qrc.mat)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.MaskPattern)
+
+	// the lower pattern wins if penalties are equal.
+	qrc, err = NewWith(text, WithMaskSelector(MaskSelectorFunc(func(Matrix, int) int { return 0 })))
+	require.NoError(t, err)
+	assert.Equal(t, 0, qrc.maskPattern)
+}
+
+func Test_WithMaskPattern_Micro(t *testing.T) {
+	for pattern := 0; pattern < len(microMaskPatterns); pattern++ {
+		qrc, err := NewMicro("12345", WithMaskPattern(pattern))
+		require.NoError(t, err)
+		assert.Equal(t, pattern, qrc.maskPattern)
+	}
+
+	_, err := NewMicro("12345", WithMaskPattern(4))
+	assert.Error(t, err)
+	_, err = NewMicro("12345", WithMaskPattern(-1))
+	assert.Error(t, err)
+
+	qrc, err := NewMicro("12345", WithMaskSelector(MaskSelectorFunc(func(_ Matrix, pattern int) int {
+		return -pattern
+	})))
+	require.NoError(t, err)
+	assert.Equal(t, 3, qrc.maskPattern)
+
+	_, err = NewRMQR("12345", WithMaskPattern(4))
+	assert.Error(t, err)
+}
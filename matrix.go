@@ -149,11 +149,11 @@ func (m *Matrix) Row(cur int) []qrvalue {
 		return nil
 	}
 
-	col := make([]qrvalue, m.height)
+	row := make([]qrvalue, m.width)
 	for w := 0; w < m.width; w++ {
-		col[w] = m.mat[w][cur]
+		row[w] = m.mat[w][cur]
 	}
-	return col
+	return row
 }
 
 // Col return a slice of column, cur should be x dimension.
@@ -165,6 +165,21 @@ func (m *Matrix) Col(cur int) []qrvalue {
 	return m.mat[cur]
 }
 
+// Paint sets the module at (x, y) to dark or light and keeps its type, it's used to
+// simulate damages or overlays on a built matrix.
+func (m *Matrix) Paint(x, y int, dark bool) error {
+	v, err := m.at(x, y)
+	if err != nil {
+		return err
+	}
+
+	v = qrvalue(v.qrtype())
+	if dark {
+		v |= 1
+	}
+	return m.set(x, y, v)
+}
+
 // Bitmap outputs the QR Code as a matrix of pixels, each represented by a single bit.
 func (m *Matrix) Bitmap() [][]bool {
 	table := make([][]bool, m.Height())
@@ -171,3 +171,24 @@ func Test_Matrix_RowAndCol(t *testing.T) {
 		})
 	}
 }
+
+func Test_Matrix_Row_Rectangle(t *testing.T) {
+	// rMQR Code is wider than its height.
+	m := newMatrix(5, 2)
+	_ = m.set(4, 1, QRValue_DATA_V1)
+
+	assert.Equal(t, []qrvalue{QRValue_INIT_V0, QRValue_INIT_V0, QRValue_INIT_V0, QRValue_INIT_V0, QRValue_DATA_V1}, m.Row(1))
+	assert.Len(t, m.Col(4), 2)
+}
+
+func Test_Matrix_Paint(t *testing.T) {
+	m := newMatrix(3, 3)
+	_ = m.set(1, 1, QRValue_FINDER_V1)
+
+	assert.NoError(t, m.Paint(1, 1, false))
+	assert.Equal(t, QRValue_FINDER_V0, m.Col(1)[1])
+	assert.NoError(t, m.Paint(0, 2, true))
+	assert.Equal(t, qrvalue(QRType_INIT|1), m.Col(0)[2])
+	assert.Equal(t, ErrorOutRangeOfW, m.Paint(3, 0, true))
+	assert.Equal(t, ErrorOutRangeOfH, m.Paint(0, -1, true))
+}
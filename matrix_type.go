@@ -20,6 +20,8 @@ const (
 	QRType_DARK     qrtype = 6 << 1
 	QRType_SPLITTER qrtype = 7 << 1
 	QRType_TIMING   qrtype = 8 << 1
+	// QRType_ALIGNMENT indicates the alignment pattern block of matrix
+	QRType_ALIGNMENT qrtype = 9 << 1
 )
 
 func (s qrtype) String() string {
@@ -40,6 +42,8 @@ func (s qrtype) String() string {
 		return "S"
 	case QRType_TIMING:
 		return "T"
+	case QRType_ALIGNMENT:
+		return "A"
 	}
 
 	return "?"
@@ -97,6 +101,11 @@ var (
 	QRValue_TIMING_V0 = qrvalue(QRType_TIMING)
 	// QRValue_TIMING_V1 represents the block has been set to TRUE
 	QRValue_TIMING_V1 = qrvalue(QRType_TIMING | 1)
+
+	// QRValue_ALIGNMENT_V0 represents the block has been set to false qrvalue(QRType_ALIGNMENT | 0)
+	QRValue_ALIGNMENT_V0 = qrvalue(QRType_ALIGNMENT)
+	// QRValue_ALIGNMENT_V1 represents the block has been set to TRUE
+	QRValue_ALIGNMENT_V1 = qrvalue(QRType_ALIGNMENT | 1)
 )
 
 func (v qrvalue) qrtype() qrtype {
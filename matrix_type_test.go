@@ -7,14 +7,15 @@ import (
 )
 
 func Test_qrtype(t *testing.T) {
-	assert.Equal(t, uint8(0b00000010), uint8(QRType_INIT))     // 1 << 1
-	assert.Equal(t, uint8(0b00000100), uint8(QRType_DATA))     // 2 << 1
-	assert.Equal(t, uint8(0b00000110), uint8(QRType_VERSION))  // 3 << 1
-	assert.Equal(t, uint8(0b00001000), uint8(QRType_FORMAT))   // 4 << 1
-	assert.Equal(t, uint8(0b00001010), uint8(QRType_FINDER))   // 5 << 1
-	assert.Equal(t, uint8(0b00001100), uint8(QRType_DARK))     // 6 << 1
-	assert.Equal(t, uint8(0b00001110), uint8(QRType_SPLITTER)) // 7 << 1
-	assert.Equal(t, uint8(0b00010000), uint8(QRType_TIMING))   // 8 << 1
+	assert.Equal(t, uint8(0b00000010), uint8(QRType_INIT))      // 1 << 1
+	assert.Equal(t, uint8(0b00000100), uint8(QRType_DATA))      // 2 << 1
+	assert.Equal(t, uint8(0b00000110), uint8(QRType_VERSION))   // 3 << 1
+	assert.Equal(t, uint8(0b00001000), uint8(QRType_FORMAT))    // 4 << 1
+	assert.Equal(t, uint8(0b00001010), uint8(QRType_FINDER))    // 5 << 1
+	assert.Equal(t, uint8(0b00001100), uint8(QRType_DARK))      // 6 << 1
+	assert.Equal(t, uint8(0b00001110), uint8(QRType_SPLITTER))  // 7 << 1
+	assert.Equal(t, uint8(0b00010000), uint8(QRType_TIMING))    // 8 << 1
+	assert.Equal(t, uint8(0b00010010), uint8(QRType_ALIGNMENT)) // 9 << 1
 
 }
 
@@ -78,6 +79,14 @@ func Test_qrvalue(t *testing.T) {
 	// QRValue_TIMING_V1
 	assert.Equal(t, QRType_TIMING, QRValue_TIMING_V1.qrtype())
 	assert.True(t, QRValue_TIMING_V1.qrbool())
+
+	// QRValue_ALIGNMENT_V0
+	assert.Equal(t, QRType_ALIGNMENT, QRValue_ALIGNMENT_V0.qrtype())
+	assert.False(t, QRValue_ALIGNMENT_V0.qrbool())
+
+	// QRValue_ALIGNMENT_V1
+	assert.Equal(t, QRType_ALIGNMENT, QRValue_ALIGNMENT_V1.qrtype())
+	assert.True(t, QRValue_ALIGNMENT_V1.qrbool())
 }
 
 func Test_qrvalue_xor(t *testing.T) {
@@ -152,6 +161,11 @@ func Test_qrtype_String(t *testing.T) {
 			t:    QRType_DARK,
 			want: "D",
 		},
+		{
+			name: "case7",
+			t:    QRType_ALIGNMENT,
+			want: "A",
+		},
 	}
 
 	for _, tt := range tests {
@@ -198,3 +212,55 @@ func Test_qrvalue_String(t *testing.T) {
 		})
 	}
 }
+
+// Test_QRType_ALIGNMENT checks alignment patterns are kept as they are after masking.
+func Test_QRType_ALIGNMENT(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func() (*QRCode, error)
+		modules int
+		dark    int
+	}{
+		{
+			name:  "version 1",
+			build: func() (*QRCode, error) { return NewWith("alignment", WithVersion(1)) },
+		},
+		{
+			// 6 patterns of 25 modules (17 dark), 2 of them overlap timing patterns
+			// by 5 modules (3 dark) which are kept as timing modules.
+			name:    "version 7",
+			build:   func() (*QRCode, error) { return NewWith("alignment", WithVersion(7)) },
+			modules: 6*25 - 2*5,
+			dark:    6*17 - 2*3,
+		},
+		{
+			// 2 patterns of 9 modules (8 dark).
+			name:    "rMQR R13x43",
+			build:   func() (*QRCode, error) { return NewRMQR("alignment", WithRMQRSize(13, 43)) },
+			modules: 2 * 9,
+			dark:    2 * 8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qrc, err := tt.build()
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			modules, dark := 0, 0
+			qrc.mat.iter(IterDirection_ROW, func(x, y int, v qrvalue) {
+				if v.qrtype() != QRType_ALIGNMENT {
+					return
+				}
+				modules++
+				if v.qrbool() {
+					dark++
+				}
+			})
+			assert.Equal(t, tt.modules, modules)
+			assert.Equal(t, tt.dark, dark)
+		})
+	}
+}
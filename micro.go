@@ -0,0 +1,371 @@
+package qrcode
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/yeqown/reedsolomon"
+	"github.com/yeqown/reedsolomon/binary"
+)
+
+const _MICRO_VERSION_COUNT = 4 // (M1 to M4)
+
+// microVersion contains information about each Micro QR Code symbol, which has
+// only one finder pattern, and exactly one block of data and error correction codewords.
+//
+// reference:
+// - ISO/IEC 18004:2015 Table 2, Table 7 and Table 9
+type microVersion struct {
+	// Ver code 1-4 for M1-M4
+	Ver int
+
+	// ECLevel error correction level, M1 only provides error detection, which is
+	// marked as ErrorCorrectionLow. Level H is not available in Micro QR Code.
+	ECLevel ecLevel
+
+	// Cap includes each type's max capacity.
+	Cap capacity
+
+	// SymbolNumber is the symbol number (0-7) of version and ECLevel in format info.
+	SymbolNumber int
+
+	// DataBits the number of data bits, the final data codeword of M1 and M3 is 4 bits.
+	DataBits int
+
+	// NumECCodewords the number of error correction codewords.
+	NumECCodewords int
+}
+
+// microVersions contains information about each Micro QR Code version.
+// NOTICE: item MUST keep sorted according to version and ECLevel (ASC).
+var microVersions = []microVersion{
+	{Ver: 1, ECLevel: ErrorCorrectionLow, Cap: capacity{Numeric: 5}, SymbolNumber: 0, DataBits: 20, NumECCodewords: 2},
+	{Ver: 2, ECLevel: ErrorCorrectionLow, Cap: capacity{Numeric: 10, AlphaNumeric: 6}, SymbolNumber: 1, DataBits: 40, NumECCodewords: 5},
+	{Ver: 2, ECLevel: ErrorCorrectionMedium, Cap: capacity{Numeric: 8, AlphaNumeric: 5}, SymbolNumber: 2, DataBits: 32, NumECCodewords: 6},
+	{Ver: 3, ECLevel: ErrorCorrectionLow, Cap: capacity{Numeric: 23, AlphaNumeric: 14, Byte: 9, JP: 6}, SymbolNumber: 3, DataBits: 84, NumECCodewords: 6},
+	{Ver: 3, ECLevel: ErrorCorrectionMedium, Cap: capacity{Numeric: 18, AlphaNumeric: 11, Byte: 7, JP: 4}, SymbolNumber: 4, DataBits: 68, NumECCodewords: 8},
+	{Ver: 4, ECLevel: ErrorCorrectionLow, Cap: capacity{Numeric: 35, AlphaNumeric: 21, Byte: 15, JP: 9}, SymbolNumber: 5, DataBits: 128, NumECCodewords: 8},
+	{Ver: 4, ECLevel: ErrorCorrectionMedium, Cap: capacity{Numeric: 30, AlphaNumeric: 18, Byte: 13, JP: 8}, SymbolNumber: 6, DataBits: 112, NumECCodewords: 10},
+	{Ver: 4, ECLevel: ErrorCorrectionQuart, Cap: capacity{Numeric: 21, AlphaNumeric: 13, Byte: 9, JP: 5}, SymbolNumber: 7, DataBits: 80, NumECCodewords: 14},
+}
+
+// microMaskPatterns are the mask patterns of Micro QR Code, the index is the
+// mask reference in format info.
+var microMaskPatterns = []maskPatternModulo{modulo1, modulo4, modulo6, modulo7}
+
+// Dimension ...
+func (v microVersion) Dimension() int {
+	return v.Ver*2 + 9
+}
+
+// NumDataCodewords the number of data codewords, including the 4 bits codeword.
+func (v microVersion) NumDataCodewords() int {
+	return (v.DataBits + 7) / 8
+}
+
+// modeIndicatorBits the length of mode indicator, 0 (M1) to 3 (M4) bits.
+func (v microVersion) modeIndicatorBits() int {
+	return v.Ver - 1
+}
+
+// modeIndicator returns the mode indicator value of mode, numeric 0, alphanumeric 1,
+// byte 2 and kanji 3.
+func (v microVersion) modeIndicator(mode encMode) uint32 {
+	switch mode {
+	case EncModeAlphanumeric:
+		return 1
+	case EncModeByte:
+		return 2
+	case EncModeKanji:
+		return 3
+	}
+
+	return 0
+}
+
+// charCountBits returns the length of character count indicator of mode,
+// 0 means mode is not available in this version.
+func (v microVersion) charCountBits(mode encMode) int {
+	switch mode {
+	case EncModeNumeric:
+		return v.Ver + 2
+	case EncModeAlphanumeric:
+		if v.Ver >= 2 {
+			return v.Ver + 1
+		}
+	case EncModeByte:
+		if v.Ver >= 3 {
+			return v.Ver + 1
+		}
+	case EncModeKanji:
+		if v.Ver >= 3 {
+			return v.Ver
+		}
+	}
+
+	return 0
+}
+
+// terminatorBits the length of terminator, 3 (M1) to 9 (M4) bits.
+func (v microVersion) terminatorBits() int {
+	return v.Ver*2 + 1
+}
+
+// segmentBitLen returns the number of bits of seg in this version,
+// -1 means seg could not be encoded in this version.
+func (v microVersion) segmentBitLen(seg Segment) int {
+	ccBits := v.charCountBits(seg.Mode)
+	if ccBits == 0 || seg.charCount() >= 1<<ccBits {
+		return -1
+	}
+
+	return v.modeIndicatorBits() + ccBits + seg.dataBitLen()
+}
+
+// formatInfo returns the 15-bit Format Information of Micro QR Code, which
+// consists of 3 bits symbol number and 2 bits mask pattern reference.
+func (v microVersion) formatInfo(maskPattern int) *binary.Binary {
+	result := binary.New()
+	result.AppendUint32(formatBitSequence[v.SymbolNumber<<2|maskPattern&0x3].micro, formatInfoBitsNum)
+
+	return result
+}
+
+// analyzeMicroVersion chooses the smallest Micro QR Code version in ec which could contain seg,
+// ver (1-4) specifies the version, 0 means any version. ec 0 means any level, the highest
+// level which could contain seg in the smallest version is chosen.
+func analyzeMicroVersion(ver int, ec ecLevel, seg Segment) (*microVersion, error) {
+	var hit, largest *microVersion
+	for i := range microVersions {
+		v := &microVersions[i]
+		if ec != 0 && v.ECLevel != ec || ver != 0 && v.Ver != ver {
+			continue
+		}
+		// higher levels of larger versions are not considered.
+		if hit != nil && v.Ver != hit.Ver {
+			break
+		}
+		if largest == nil || v.DataBits > largest.DataBits {
+			largest = v
+		}
+
+		if n := v.segmentBitLen(seg); n >= 0 && n <= v.DataBits {
+			hit = v
+		}
+	}
+	if hit != nil {
+		return hit, nil
+	}
+	debugLogf("mismatched micro version, ver: %d, ec: %v", ver, ec)
+
+	if largest == nil {
+		if ver != 0 {
+			return nil, fmt.Errorf("%w: %s is not available in M%d", errInvalidErrorCorrectionLevel, ecLevelName(ec), ver)
+		}
+		return nil, fmt.Errorf("%w: %s is not available in Micro QR Code", errInvalidErrorCorrectionLevel, ecLevelName(ec))
+	}
+
+	return nil, &DataTooLongError{
+		Needed:  largest.segmentBitLen(seg),
+		Max:     largest.DataBits,
+		Version: largest.Ver,
+		ECLevel: largest.ECLevel,
+		symbol:  fmt.Sprintf("M%d", largest.Ver),
+		err:     errAnalyzeVersionFailed,
+	}
+}
+
+// NewMicro generate a Micro QR Code (M1 to M4) which has only one finder pattern,
+// it's much smaller than version 1 QR Code but contains only a few characters.
+// WithVersion (1 to 4 for M1 to M4), WithErrorCorrectionLevel (H is not available)
+// and WithEncodingMode are supported, ECI, FNC1 and Structured Append are not.
+// Without WithErrorCorrectionLevel, the smallest symbol is chosen from M1 (error
+// detection only) upward, and in it the highest level which could contain text,
+// e.g. "1" is encoded in M1, while Q requires M4. The symbol is saved by Writer
+// as a regular Matrix.
+func NewMicro[T ~string | ~[]byte](text T, opts ...EncodeOption) (*QRCode, error) {
+	dst := DefaultEncodingOption()
+	// zero means the level is not specified by WithErrorCorrectionLevel.
+	dst.EcLevel = 0
+	for _, opt := range opts {
+		opt.apply(dst)
+	}
+
+	qrc := &QRCode{
+		sourceText:     string(toBytes(text)),
+		encodingOption: dst,
+	}
+	if err := qrc.initMicro(); err != nil {
+		return nil, err
+	}
+
+	qrc.maskingMicro()
+
+	return qrc, nil
+}
+
+// initMicro fill QRCode instance as Micro QR Code from settings and sourceText.
+func (q *QRCode) initMicro() (err error) {
+	opt := q.encodingOption
+	if opt.ECI != nil || opt.FNC1 != nil || opt.structuredAppend != nil {
+		return fmt.Errorf("init: ECI, FNC1 and Structured Append are not available in Micro QR Code")
+	}
+	if opt.Verify {
+		return fmt.Errorf("init: verify is not available in Micro QR Code")
+	}
+	if opt.QArt != nil {
+		return fmt.Errorf("init: QArt is not available in Micro QR Code")
+	}
+	if opt.MaskPattern != nil && (*opt.MaskPattern < 0 || *opt.MaskPattern >= len(microMaskPatterns)) {
+		return fmt.Errorf("init: invalid Micro QR Code mask pattern: %d", *opt.MaskPattern)
+	}
+	if opt.Version > _MICRO_VERSION_COUNT {
+		return fmt.Errorf("init: invalid Micro QR Code version: M%d", opt.Version)
+	}
+
+	seg, err := q.singleSegment()
+	if err != nil {
+		return err
+	}
+
+	q.segments = []Segment{seg}
+	if q.micro, err = analyzeMicroVersion(opt.Version, opt.EcLevel, q.segments[0]); err != nil {
+		return fmt.Errorf("init: calc micro version failed: %w", err)
+	}
+	opt.Version = q.micro.Ver
+	opt.EcLevel = q.micro.ECLevel
+
+	q.encoder = newEncoder(opt.EncMode, opt.EcLevel, version{})
+	q.encoder.micro = q.micro
+	data, err := q.encoder.EncodeSegments(q.segments)
+	if err != nil {
+		return fmt.Errorf("could not encode data: %w", err)
+	}
+
+	// the final 4 bits codeword of M1 and M3 is padded to 8 bits while calculating
+	// error correction codewords, but only 4 bits are placed in matrix.
+	// NOTICE: binary.Copy shares underlying bytes, so padded must be a new one.
+	padded := binary.New()
+	padded.Append(data)
+	padded.AppendNumBools(q.micro.NumDataCodewords()*8-data.Len(), false)
+	full := reedsolomon.Encode(padded, q.micro.NumECCodewords)
+	ec, err := full.Subset(padded.Len(), full.Len())
+	if err != nil {
+		return fmt.Errorf("could not encode error correction: %w", err)
+	}
+	q.dataBSet = data
+	q.ecBSet = ec
+	q.dataBSet.Append(q.ecBSet)
+
+	q.mat = newMatrix(q.micro.Dimension(), q.micro.Dimension())
+	q.prefillMicroMatrix()
+
+	return nil
+}
+
+// breakUpMicro appends terminator and padding bits of Micro QR Code, which
+// depends on version, and the final data codeword of M1 and M3 is 4 bits.
+func (e *encoder) breakUpMicro() error {
+	v := e.micro
+	if e.dst.Len() > v.DataBits {
+		return &DataTooLongError{
+			Needed:  e.dst.Len(),
+			Max:     v.DataBits,
+			Version: v.Ver,
+			ECLevel: v.ECLevel,
+			symbol:  fmt.Sprintf("M%d", v.Ver),
+		}
+	}
+
+	e.dst.AppendNumBools(min(v.terminatorBits(), v.DataBits-e.dst.Len()), false)
+	if mod := e.dst.Len() % 8; mod != 0 {
+		e.dst.AppendNumBools(min(8-mod, v.DataBits-e.dst.Len()), false)
+	}
+
+	for i := 1; v.DataBits-e.dst.Len() >= 8; i++ {
+		if i%2 == 1 {
+			e.dst.Append(paddingByte1)
+		} else {
+			e.dst.Append(paddingByte2)
+		}
+	}
+	// the final 4 bits codeword is padded with 0000.
+	e.dst.AppendNumBools(v.DataBits-e.dst.Len(), false)
+
+	return nil
+}
+
+// prefillMicroMatrix with finder, separator, timing patterns and reserved format info.
+func (q *QRCode) prefillMicroMatrix() {
+	dimension := q.micro.Dimension()
+
+	addFinder(q.mat, 0, 0)
+	addSplitter(q.mat, 7, 7, dimension)
+
+	// timing patterns are along the top row and the left column.
+	for pos := 8; pos < dimension; pos++ {
+		value := QRValue_TIMING_V0
+		if pos%2 == 0 {
+			value = QRValue_TIMING_V1
+		}
+		_ = q.mat.set(pos, 0, value)
+		_ = q.mat.set(0, pos, value)
+	}
+
+	for pos := 1; pos <= 8; pos++ {
+		_ = q.mat.set(8, pos, QRValue_FORMAT_V0)
+		_ = q.mat.set(pos, 8, QRValue_FORMAT_V0)
+	}
+}
+
+// fillMicroFormatInfo fills format info from the most significant bit, along
+// the row 8 from left to right, and then the column 8 from bottom to top.
+func fillMicroFormatInfo(m *Matrix, fmtBSet *binary.Binary) {
+	for pos := 0; pos < formatInfoBitsNum; pos++ {
+		value := QRValue_FORMAT_V0
+		if fmtBSet.At(pos) {
+			value = QRValue_FORMAT_V1
+		}
+
+		if pos < 8 {
+			_ = m.set(pos+1, 8, value)
+		} else {
+			_ = m.set(8, 15-pos, value)
+		}
+	}
+}
+
+// maskingMicro fills data into matrix and applies each mask pattern of Micro QR Code,
+// and then chooses the mask with the highest score. The mask with the lowest penalty
+// is chosen instead if MaskSelector is specified.
+func (q *QRCode) maskingMicro() {
+	cpy := q.mat.Copy()
+	fillDataColumns(cpy, q.dataBSet, cpy.Width()-1)
+
+	var (
+		best      *Matrix
+		bestScore = math.MinInt32
+		selector  = q.encodingOption.MaskSelector
+	)
+	for i, mode := range microMaskPatterns {
+		if p := q.encodingOption.MaskPattern; p != nil && *p != i {
+			continue
+		}
+
+		mat := cpy.Copy()
+		q.xorMask(mat, newMask(q.mat, mode))
+		fillMicroFormatInfo(mat, q.micro.formatInfo(i))
+
+		score := evaluationMicro(mat)
+		if selector != nil {
+			score = -selector.Penalty(*mat, i)
+		}
+		debugLogf("micro mask: %d, score: %d, current highest: %d", i, score, bestScore)
+		if score > bestScore {
+			best, bestScore = mat, score
+			q.maskPattern = i
+		}
+	}
+
+	q.mat = best
+}
@@ -0,0 +1,298 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yeqown/reedsolomon"
+	"github.com/yeqown/reedsolomon/binary"
+)
+
+func Test_analyzeMicroVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		ver     int
+		ec      ecLevel
+		seg     Segment
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "5 digits in M1",
+			ec:   ErrorCorrectionLow,
+			seg:  Segment{Mode: EncModeNumeric, Data: "12345"},
+			want: 1,
+		},
+		{
+			name: "6 digits in M2",
+			ec:   ErrorCorrectionLow,
+			seg:  Segment{Mode: EncModeNumeric, Data: "123456"},
+			want: 2,
+		},
+		{
+			name: "alphanumeric is not available in M1",
+			ec:   ErrorCorrectionLow,
+			seg:  Segment{Mode: EncModeAlphanumeric, Data: "A"},
+			want: 2,
+		},
+		{
+			name: "byte is available since M3",
+			ec:   ErrorCorrectionMedium,
+			seg:  Segment{Mode: EncModeByte, Data: "abcdefg"},
+			want: 3,
+		},
+		{
+			name: "M4-Q only",
+			ec:   ErrorCorrectionQuart,
+			seg:  Segment{Mode: EncModeNumeric, Data: "1"},
+			want: 4,
+		},
+		{
+			name:    "too long",
+			ec:      ErrorCorrectionQuart,
+			seg:     Segment{Mode: EncModeByte, Data: "abcdefghij"},
+			wantErr: true,
+		},
+		{
+			name:    "H is not available",
+			ec:      ErrorCorrectionHighest,
+			seg:     Segment{Mode: EncModeNumeric, Data: "1"},
+			wantErr: true,
+		},
+		{
+			name: "specified version",
+			ver:  3,
+			ec:   ErrorCorrectionLow,
+			seg:  Segment{Mode: EncModeNumeric, Data: "1"},
+			want: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := analyzeMicroVersion(tt.ver, tt.ec, tt.seg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Ver)
+			assert.Equal(t, tt.ec, got.ECLevel)
+		})
+	}
+}
+
+func Test_microVersions_capacity(t *testing.T) {
+	// capacity table should match the bits of each mode.
+	for _, v := range microVersions {
+		for mode, n := range map[encMode]int{
+			EncModeNumeric:      v.Cap.Numeric,
+			EncModeAlphanumeric: v.Cap.AlphaNumeric,
+			EncModeByte:         v.Cap.Byte,
+		} {
+			if n == 0 {
+				assert.Equal(t, -1, v.segmentBitLen(Segment{Mode: mode, Data: "1"}))
+				continue
+			}
+
+			fit := v.segmentBitLen(Segment{Mode: mode, Data: strings.Repeat("1", n)})
+			assert.LessOrEqual(t, fit, v.DataBits, "M%d-%d %s", v.Ver, v.ECLevel, getEncModeName(mode))
+			over := v.segmentBitLen(Segment{Mode: mode, Data: strings.Repeat("1", n+1)})
+			if over >= 0 {
+				assert.Greater(t, over, v.DataBits, "M%d-%d %s", v.Ver, v.ECLevel, getEncModeName(mode))
+			}
+		}
+	}
+}
+
+func Test_encoder_breakUpMicro(t *testing.T) {
+	// "01234567" in M2-L: 0 1000 0000001100 0101011001 1000011, then 5 bits terminator.
+	e := newEncoder(EncModeNumeric, ErrorCorrectionLow, version{})
+	e.micro = &microVersions[1]
+	got, err := e.EncodeSegments([]Segment{{Mode: EncModeNumeric, Data: "01234567"}})
+	require.NoError(t, err)
+	assert.Equal(t, "0"+"1000"+"0000001100"+"0101011001"+"1000011"+"00000"+"000", bitsString(got))
+
+	// M1 ends with 4 bits codeword, which is too short for padding byte.
+	e = newEncoder(EncModeNumeric, ErrorCorrectionLow, version{})
+	e.micro = &microVersions[0]
+	got, err = e.EncodeSegments([]Segment{{Mode: EncModeNumeric, Data: "1"}})
+	require.NoError(t, err)
+	assert.Equal(t, "001"+"0001"+"000"+"000000"+"0000", bitsString(got))
+}
+
+// readMicro reads format info, mask and data bits from a Micro QR Code matrix.
+func readMicro(t *testing.T, m *Matrix) (v *microVersion, mask int, bits *binary.Binary) {
+	t.Helper()
+
+	format := uint32(0)
+	for pos := 0; pos < formatInfoBitsNum; pos++ {
+		x, y := pos+1, 8
+		if pos >= 8 {
+			x, y = 8, 15-pos
+		}
+		value, _ := m.at(x, y)
+		format = format<<1 | uint32(value&1)
+	}
+
+	idx := -1
+	for i, seq := range formatBitSequence[:32] {
+		if seq.micro == format {
+			idx = i
+		}
+	}
+	require.GreaterOrEqual(t, idx, 0, "format info not found: %015b", format)
+	for i := range microVersions {
+		if microVersions[i].SymbolNumber == idx>>2 {
+			v = &microVersions[i]
+		}
+	}
+	mask = idx & 0x3
+
+	// collect data modules in placement order by filling a fresh matrix with index.
+	layout := newMatrix(m.Width(), m.Height())
+	(&QRCode{micro: v, mat: layout}).prefillMicroMatrix()
+	moduloFn := getModuloFunc(microMaskPatterns[mask])
+
+	bits = binary.New()
+	upward := true
+	for right := m.Width() - 1; right >= 1; right -= 2 {
+		for i := 0; i < m.Height(); i++ {
+			y := i
+			if upward {
+				y = m.Height() - 1 - i
+			}
+			for x := right; x >= right-1; x-- {
+				if value, _ := layout.at(x, y); value.qrtype() != QRType_INIT {
+					continue
+				}
+				value, _ := m.at(x, y)
+				bits.AppendBools(value.qrbool() != moduloFn(x, y))
+			}
+		}
+		upward = !upward
+	}
+
+	return v, mask, bits
+}
+
+func Test_NewMicro(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts []EncodeOption
+		ver  int
+		mode encMode
+	}{
+		{name: "M1 numeric", text: "12345", opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionLow)}, ver: 1, mode: EncModeNumeric},
+		{name: "M2 alphanumeric", text: "AB-12", opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionMedium)}, ver: 2, mode: EncModeAlphanumeric},
+		{name: "M3 byte", text: "abc", opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionMedium)}, ver: 3, mode: EncModeByte},
+		{name: "M3 default", text: "hello", ver: 3, mode: EncModeByte},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qrc, err := NewMicro(tt.text, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.ver*2+9, qrc.Dimension())
+
+			v, _, bits := readMicro(t, qrc.mat)
+			assert.Equal(t, qrc.micro, v)
+			require.Equal(t, v.DataBits+v.NumECCodewords*8, bits.Len())
+
+			// error correction codewords should match the data codewords.
+			data, _ := bits.Subset(0, v.DataBits)
+			ec, _ := bits.Subset(v.DataBits, bits.Len())
+			data.AppendNumBools(v.NumDataCodewords()*8-v.DataBits, false)
+			full := reedsolomon.Encode(data, v.NumECCodewords)
+			wantEC, _ := full.Subset(data.Len(), full.Len())
+			assert.True(t, wantEC.EqualTo(ec))
+
+			// mode indicator and character count.
+			pos := 0
+			readN := func(n int) uint32 {
+				var value uint32
+				for i := 0; i < n; i++ {
+					value <<= 1
+					if bits.At(pos) {
+						value |= 1
+					}
+					pos++
+				}
+				return value
+			}
+			assert.Equal(t, v.modeIndicator(tt.mode), readN(v.modeIndicatorBits()))
+			assert.Equal(t, uint32(len(tt.text)), readN(v.charCountBits(tt.mode)))
+		})
+	}
+}
+
+func Test_NewMicro_DefaultLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts []EncodeOption
+		ver  int
+		ec   ecLevel
+	}{
+		{name: "M1 error detection only", text: "1", ver: 1, ec: ErrorCorrectionLow},
+		{name: "highest level in M2", text: "123456", ver: 2, ec: ErrorCorrectionMedium},
+		{name: "M2-L", text: "123456789", ver: 2, ec: ErrorCorrectionLow},
+		{name: "highest level in M4", text: "1", opts: []EncodeOption{WithVersion(4)}, ver: 4, ec: ErrorCorrectionQuart},
+		{name: "specified M1", text: "1", opts: []EncodeOption{WithVersion(1)}, ver: 1, ec: ErrorCorrectionLow},
+		{name: "specified level", text: "1", opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionQuart)}, ver: 4, ec: ErrorCorrectionQuart},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qrc, err := NewMicro(tt.text, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.ver, qrc.micro.Ver)
+			assert.Equal(t, tt.ec, qrc.micro.ECLevel)
+			assert.Equal(t, tt.ec, qrc.Info().ECLevel)
+		})
+	}
+
+	// the level is not available in the version.
+	_, err := NewMicro("1", WithVersion(1), WithErrorCorrectionLevel(ErrorCorrectionQuart))
+	assert.ErrorIs(t, err, errInvalidErrorCorrectionLevel)
+	_, err = NewMicro("1", WithErrorCorrectionLevel(ErrorCorrectionHighest))
+	assert.ErrorIs(t, err, errInvalidErrorCorrectionLevel)
+
+	// M4-L holds the most, which is reported without a specified level.
+	_, err = NewMicro(strings.Repeat("a", 16))
+	var tooLong *DataTooLongError
+	require.ErrorAs(t, err, &tooLong)
+	assert.Equal(t, ErrorCorrectionLow, tooLong.ECLevel)
+	assert.Equal(t, 128, tooLong.Max)
+}
+
+func Test_NewMicro_Invalid(t *testing.T) {
+	_, err := NewMicro("abcdefghijklmnop", WithErrorCorrectionLevel(ErrorCorrectionLow))
+	assert.Error(t, err)
+
+	_, err = NewMicro("123", WithErrorCorrectionLevel(ErrorCorrectionLow), WithUTF8ECI())
+	assert.Error(t, err)
+
+	_, err = NewMicro("123", WithVersion(5))
+	assert.Error(t, err)
+}
+
+func Test_evaluationMicro(t *testing.T) {
+	m := newMatrix(11, 11)
+	// 3 dark modules in the right edge, and 5 in the bottom edge.
+	for i := 1; i <= 3; i++ {
+		_ = m.set(10, i, QRValue_DATA_V1)
+	}
+	for i := 1; i <= 5; i++ {
+		_ = m.set(i, 10, QRValue_DATA_V1)
+	}
+	assert.Equal(t, 3*16+5, evaluationMicro(m))
+
+	// timing modules in the corner are not counted.
+	_ = m.set(10, 0, QRValue_TIMING_V1)
+	_ = m.set(0, 10, QRValue_TIMING_V1)
+	assert.Equal(t, 3*16+5, evaluationMicro(m))
+}
@@ -0,0 +1,337 @@
+package payload
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+// VCardVersion is the version of vCard.
+type VCardVersion string
+
+const (
+	// VCard30 vCard 3.0 (RFC 2426), which is supported by most scanners.
+	VCard30 VCardVersion = "3.0"
+	// VCard40 vCard 4.0 (RFC 6350).
+	VCard40 VCardVersion = "4.0"
+)
+
+const meCardPrefix = "MECARD:"
+
+// Contact is a business card, which could be rendered as vCard or MeCard.
+type Contact struct {
+	// FirstName and LastName of the person.
+	FirstName, LastName string
+
+	// FormattedName the name to display, empty means FirstName and LastName joined
+	// by a space. It's not included in MeCard.
+	FormattedName string
+
+	// Phones telephone numbers, the first one is preferred.
+	Phones []Phone
+
+	// Emails email addresses, the first one is preferred.
+	Emails []string
+
+	// Addresses postal addresses, the first one is preferred.
+	Addresses []Address
+
+	// Org organization name.
+	Org string
+
+	// Title job title, it's not included in MeCard.
+	Title string
+
+	// URL of website.
+	URL string
+
+	// Note any text, which may contain new lines.
+	Note string
+}
+
+// Phone is a telephone number of Contact.
+type Phone struct {
+	Number string
+
+	// Type such as "cell", "work", "home" and "fax", empty means no type.
+	// It's not included in MeCard.
+	Type string
+}
+
+// Address is a postal address of Contact.
+type Address struct {
+	Street, City, Region, PostalCode, Country string
+
+	// Type such as "work" and "home", empty means no type. It's not included in MeCard.
+	Type string
+}
+
+// formattedName returns FormattedName with default one.
+func (c Contact) formattedName() string {
+	if c.FormattedName != "" {
+		return c.FormattedName
+	}
+
+	return strings.TrimSpace(c.FirstName + " " + c.LastName)
+}
+
+// VCard renders the contact as vCard of version, lines end with CRLF and are
+// folded at 75 octets.
+//
+// reference:
+// - https://www.rfc-editor.org/rfc/rfc2426 (vCard 3.0)
+// - https://www.rfc-editor.org/rfc/rfc6350 (vCard 4.0)
+func (c Contact) VCard(version VCardVersion) string {
+	if version != VCard40 {
+		version = VCard30
+	}
+	// typeParam returns ";TYPE=t", types are upper case in 3.0 and lower case in 4.0.
+	typeParam := func(t string) string {
+		switch {
+		case t == "":
+			return ""
+		case version == VCard30:
+			return ";TYPE=" + strings.ToUpper(t)
+		default:
+			return ";TYPE=" + strings.ToLower(t)
+		}
+	}
+
+	var b strings.Builder
+	writeContentLine(&b, "BEGIN:VCARD")
+	writeContentLine(&b, "VERSION:"+string(version))
+	if c.FirstName != "" || c.LastName != "" || version == VCard30 {
+		writeContentLine(&b, "N:"+escapeText(c.LastName)+";"+escapeText(c.FirstName)+";;;")
+	}
+	writeContentLine(&b, "FN:"+escapeText(c.formattedName()))
+	if c.Org != "" {
+		writeContentLine(&b, "ORG:"+escapeText(c.Org))
+	}
+	if c.Title != "" {
+		writeContentLine(&b, "TITLE:"+escapeText(c.Title))
+	}
+	for _, p := range c.Phones {
+		if version == VCard40 {
+			// the value of TEL is a URI in vCard 4.0.
+			number := strings.Join(strings.Fields(p.Number), "-")
+			writeContentLine(&b, "TEL;VALUE=uri"+typeParam(p.Type)+":tel:"+number)
+			continue
+		}
+		writeContentLine(&b, "TEL"+typeParam(p.Type)+":"+escapeText(p.Number))
+	}
+	for _, e := range c.Emails {
+		writeContentLine(&b, "EMAIL:"+escapeText(e))
+	}
+	for _, a := range c.Addresses {
+		writeContentLine(&b, "ADR"+typeParam(a.Type)+":;;"+strings.Join([]string{
+			escapeText(a.Street), escapeText(a.City), escapeText(a.Region),
+			escapeText(a.PostalCode), escapeText(a.Country),
+		}, ";"))
+	}
+	if c.URL != "" {
+		// URL is a URI value, which is not escaped.
+		writeContentLine(&b, "URL:"+c.URL)
+	}
+	if c.Note != "" {
+		writeContentLine(&b, "NOTE:"+escapeText(c.Note))
+	}
+	writeContentLine(&b, "END:VCARD")
+
+	return b.String()
+}
+
+// MeCard renders the contact as MeCard, which is more compact than vCard but
+// FormattedName, Title and types of phones and addresses are dropped.
+//
+// reference:
+// - https://github.com/zxing/zxing/wiki/Barcode-Contents#contact-information
+func (c Contact) MeCard() string {
+	var b strings.Builder
+	b.WriteString(meCardPrefix)
+
+	name := fieldEscaper.Replace(c.LastName)
+	if c.FirstName != "" {
+		name += "," + fieldEscaper.Replace(c.FirstName)
+	}
+	if name != "" {
+		b.WriteString("N:" + name + ";")
+	}
+	writeField(&b, "ORG", c.Org)
+	for _, p := range c.Phones {
+		writeField(&b, "TEL", p.Number)
+	}
+	for _, e := range c.Emails {
+		writeField(&b, "EMAIL", e)
+	}
+	for _, a := range c.Addresses {
+		// PO box, room number, street, city, region, postal code and country.
+		b.WriteString("ADR:,," + strings.Join([]string{
+			fieldEscaper.Replace(a.Street), fieldEscaper.Replace(a.City), fieldEscaper.Replace(a.Region),
+			fieldEscaper.Replace(a.PostalCode), fieldEscaper.Replace(a.Country),
+		}, ",") + ";")
+	}
+	writeField(&b, "URL", c.URL)
+	writeField(&b, "NOTE", c.Note)
+	b.WriteByte(';')
+
+	return b.String()
+}
+
+// Fit returns the contact in the richest form which fits in maxVersion with opts,
+// the capacity is checked by qrcode.Estimate. The vCard of version is tried first,
+// then MeCard, and then vCard without note, addresses, URL, title, phones and emails
+// except the first one, and organization in order.
+func (c Contact) Fit(version VCardVersion, maxVersion int, opts ...qrcode.EncodeOption) (string, error) {
+	opts = append(opts[:len(opts):len(opts)], qrcode.WithMaximumVersion(maxVersion))
+	fits := func(s string) bool {
+		_, err := qrcode.Estimate(s, opts...)
+		return err == nil
+	}
+
+	if s := c.VCard(version); fits(s) {
+		return s, nil
+	}
+	if s := c.MeCard(); fits(s) {
+		return s, nil
+	}
+
+	trims := []func(c *Contact){
+		func(c *Contact) { c.Note = "" },
+		func(c *Contact) { c.Addresses = nil },
+		func(c *Contact) { c.URL = "" },
+		func(c *Contact) { c.Title = "" },
+		func(c *Contact) {
+			if len(c.Phones) > 1 {
+				c.Phones = c.Phones[:1]
+			}
+			if len(c.Emails) > 1 {
+				c.Emails = c.Emails[:1]
+			}
+		},
+		func(c *Contact) { c.Org = "" },
+	}
+	trimmed := c
+	for _, trim := range trims {
+		trim(&trimmed)
+		if s := trimmed.VCard(version); fits(s) {
+			return s, nil
+		}
+	}
+
+	s := trimmed.VCard(version)
+	_, err := qrcode.Estimate(s, opts...)
+	return "", fmt.Errorf("payload: contact doesn't fit in version %d: %w", maxVersion, err)
+}
+
+// ParseVCard parses vCard 3.0 or 4.0, unknown properties and parameters are ignored.
+func ParseVCard(s string) (Contact, error) {
+	var (
+		c     Contact
+		begin bool
+		end   bool
+	)
+
+	for _, line := range unfoldLines(s) {
+		if end {
+			return c, fmt.Errorf("payload: %w: vCard: unexpected line after END", ErrInvalidPayload)
+		}
+
+		name, params, value, ok := parseContentLine(line)
+		if !ok {
+			return c, fmt.Errorf("payload: %w: vCard: invalid line %q", ErrInvalidPayload, line)
+		}
+		if !begin {
+			if name != "BEGIN" || !strings.EqualFold(value, "VCARD") {
+				return c, fmt.Errorf("payload: %w: vCard must start with BEGIN:VCARD", ErrInvalidPayload)
+			}
+			begin = true
+			continue
+		}
+
+		typ := strings.ToLower(params["TYPE"])
+		switch name {
+		case "END":
+			end = true
+		case "N":
+			parts := splitText(value, ';')
+			c.LastName = parts[0]
+			if len(parts) > 1 {
+				c.FirstName = parts[1]
+			}
+		case "FN":
+			c.FormattedName = unescapeText(value)
+		case "ORG":
+			// organizational units follow the name of organization.
+			c.Org = splitText(value, ';')[0]
+		case "TITLE":
+			c.Title = unescapeText(value)
+		case "TEL":
+			c.Phones = append(c.Phones, Phone{Number: strings.TrimPrefix(unescapeText(value), "tel:"), Type: typ})
+		case "EMAIL":
+			c.Emails = append(c.Emails, unescapeText(value))
+		case "ADR":
+			parts := append(splitText(value, ';'), make([]string, 7)...)
+			c.Addresses = append(c.Addresses, Address{
+				Street: parts[2], City: parts[3], Region: parts[4], PostalCode: parts[5], Country: parts[6], Type: typ,
+			})
+		case "URL":
+			c.URL = value
+		case "NOTE":
+			c.Note = unescapeText(value)
+		}
+	}
+
+	if !end {
+		return c, fmt.Errorf("payload: %w: vCard must end with END:VCARD", ErrInvalidPayload)
+	}
+
+	return c, nil
+}
+
+// ParseMeCard parses MeCard, unknown fields are ignored.
+func ParseMeCard(s string) (Contact, error) {
+	var c Contact
+	if !strings.HasPrefix(s, meCardPrefix) {
+		return c, fmt.Errorf("payload: %w: MeCard must start with %q", ErrInvalidPayload, meCardPrefix)
+	}
+
+	fields, err := parseFields(s[len(meCardPrefix):])
+	if err != nil {
+		return c, fmt.Errorf("payload: %w: MeCard: %v", ErrInvalidPayload, err)
+	}
+	for _, f := range fields {
+		switch f.key {
+		case "N":
+			parts := splitEscaped(f.raw, ',')
+			c.LastName = parts[0]
+			if len(parts) > 1 {
+				c.FirstName = parts[1]
+			}
+		case "ORG":
+			c.Org = f.value
+		case "TEL":
+			c.Phones = append(c.Phones, Phone{Number: f.value})
+		case "EMAIL":
+			c.Emails = append(c.Emails, f.value)
+		case "ADR":
+			parts := splitEscaped(f.raw, ',')
+			if len(parts) != 7 {
+				c.Addresses = append(c.Addresses, Address{Street: f.value})
+				continue
+			}
+			c.Addresses = append(c.Addresses, Address{
+				Street: parts[2], City: parts[3], Region: parts[4], PostalCode: parts[5], Country: parts[6],
+			})
+		case "URL":
+			c.URL = f.value
+		case "NOTE":
+			c.Note = f.value
+		}
+	}
+	if c.FirstName == "" && c.LastName == "" {
+		return c, fmt.Errorf("payload: %w: MeCard name is required", ErrInvalidPayload)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,189 @@
+package payload
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testContact = Contact{
+	FirstName:     "Owen",
+	LastName:      "Sean",
+	FormattedName: "Sean Owen",
+	Phones:        []Phone{{Number: "+1 555 0100", Type: "cell"}, {Number: "+1 555 0199", Type: "work"}},
+	Emails:        []string{"srowen@example.com", "owen@example.org"},
+	Addresses: []Address{{
+		Street: "76 9th Ave, 4th Floor", City: "New York", Region: "NY", PostalCode: "10011", Country: "USA", Type: "work",
+	}},
+	Org:   "Example; Inc.",
+	Title: "Engineer",
+	URL:   "https://example.com",
+	Note:  "line 1\nline 2, with comma",
+}
+
+func Test_Contact_VCard30(t *testing.T) {
+	want := "BEGIN:VCARD\r\n" +
+		"VERSION:3.0\r\n" +
+		"N:Sean;Owen;;;\r\n" +
+		"FN:Sean Owen\r\n" +
+		"ORG:Example\\; Inc.\r\n" +
+		"TITLE:Engineer\r\n" +
+		"TEL;TYPE=CELL:+1 555 0100\r\n" +
+		"TEL;TYPE=WORK:+1 555 0199\r\n" +
+		"EMAIL:srowen@example.com\r\n" +
+		"EMAIL:owen@example.org\r\n" +
+		"ADR;TYPE=WORK:;;76 9th Ave\\, 4th Floor;New York;NY;10011;USA\r\n" +
+		"URL:https://example.com\r\n" +
+		"NOTE:line 1\\nline 2\\, with comma\r\n" +
+		"END:VCARD\r\n"
+	assert.Equal(t, want, testContact.VCard(VCard30))
+
+	got, err := ParseVCard(want)
+	require.NoError(t, err)
+	assert.Equal(t, testContact, got)
+}
+
+func Test_Contact_VCard40(t *testing.T) {
+	s := testContact.VCard(VCard40)
+	assert.True(t, strings.HasPrefix(s, "BEGIN:VCARD\r\nVERSION:4.0\r\n"))
+	assert.Contains(t, s, "TEL;VALUE=uri;TYPE=cell:tel:+1-555-0100\r\n")
+	assert.Contains(t, s, "ADR;TYPE=work:;;")
+
+	got, err := ParseVCard(s)
+	require.NoError(t, err)
+	want := testContact
+	want.Phones = []Phone{{Number: "+1-555-0100", Type: "cell"}, {Number: "+1-555-0199", Type: "work"}}
+	assert.Equal(t, want, got)
+
+	// URL is a URI value, which is not escaped.
+	c := Contact{FormattedName: "Example", URL: "https://x.y/?a=1;b=2,3"}
+	s = c.VCard(VCard40)
+	assert.Contains(t, s, "\r\nURL:https://x.y/?a=1;b=2,3\r\n")
+	got, err = ParseVCard(s)
+	require.NoError(t, err)
+	assert.Equal(t, c.URL, got.URL)
+
+	// N is optional in vCard 4.0.
+	s = Contact{FormattedName: "Example Inc."}.VCard(VCard40)
+	assert.NotContains(t, s, "\r\nN:")
+}
+
+func Test_Contact_VCard_Folding(t *testing.T) {
+	c := Contact{FirstName: "名", LastName: "姓", Note: strings.Repeat("長いメモ, ", 20)}
+	s := c.VCard(VCard30)
+
+	lines := strings.Split(strings.TrimSuffix(s, "\r\n"), "\r\n")
+	assert.Greater(t, len(lines), 6)
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, strings.ToValidUTF8(line, "?") == line, "line breaks UTF-8 character: %q", line)
+	}
+
+	got, err := ParseVCard(s)
+	require.NoError(t, err)
+	assert.Equal(t, c.Note, got.Note)
+	assert.Equal(t, "名 姓", got.FormattedName)
+}
+
+func Test_writeContentLine_InvalidUTF8(t *testing.T) {
+	var b strings.Builder
+	writeContentLine(&b, "NOTE:"+strings.Repeat("\x80", 100))
+
+	// it must terminate even if no character starts within the limit.
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Equal(t, "NOTE:"+strings.Repeat("\x80", 100), strings.Join(unfoldLines(b.String()), ""))
+}
+
+func Test_ParseVCard(t *testing.T) {
+	// LF line endings, tab folding, lower case names and unknown properties.
+	got, err := ParseVCard("begin:vcard\nversion:3.0\nfn:Jane\n\t Doe\nX-SOCIAL:jane\nORG:Acme;R&D\nend:vcard\n")
+	require.NoError(t, err)
+	assert.Equal(t, Contact{FormattedName: "Jane Doe", Org: "Acme"}, got)
+
+	for _, s := range []string{
+		"VERSION:3.0\r\nEND:VCARD\r\n",
+		"BEGIN:VCARD\r\nFN:Jane\r\n",
+		"BEGIN:VCARD\r\nFN Jane\r\nEND:VCARD\r\n",
+		"BEGIN:VCARD\r\nEND:VCARD\r\nFN:Jane\r\n",
+	} {
+		_, err = ParseVCard(s)
+		assert.ErrorIs(t, err, ErrInvalidPayload, s)
+	}
+}
+
+func Test_Contact_MeCard(t *testing.T) {
+	want := `MECARD:N:Sean,Owen;ORG:Example\; Inc.;TEL:+1 555 0100;TEL:+1 555 0199;` +
+		`EMAIL:srowen@example.com;EMAIL:owen@example.org;` +
+		`ADR:,,76 9th Ave\, 4th Floor,New York,NY,10011,USA;URL:https\://example.com;` +
+		"NOTE:line 1\nline 2\\, with comma;;"
+	assert.Equal(t, want, testContact.MeCard())
+
+	got, err := ParseMeCard(want)
+	require.NoError(t, err)
+	expected := testContact
+	expected.FormattedName, expected.Title = "", ""
+	expected.Phones = []Phone{{Number: "+1 555 0100"}, {Number: "+1 555 0199"}}
+	expected.Addresses = []Address{testContact.Addresses[0]}
+	expected.Addresses[0].Type = ""
+	assert.Equal(t, expected, got)
+
+	got, err = ParseMeCard(`MECARD:N:Doe\,Jr.,Jane;ADR:1 Main St;;`)
+	require.NoError(t, err)
+	assert.Equal(t, Contact{LastName: "Doe,Jr.", FirstName: "Jane", Addresses: []Address{{Street: "1 Main St"}}}, got)
+
+	_, err = ParseMeCard(`MECARD:TEL:123;;`)
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+	_, err = ParseMeCard(`WIFI:S:home;;`)
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func Test_Contact_Fit(t *testing.T) {
+	opts := []qrcode.EncodeOption{qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionMedium)}
+
+	s, err := testContact.Fit(VCard30, 40, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, testContact.VCard(VCard30), s)
+
+	// version returns the version that s would get.
+	version := func(s string) int {
+		est, err := qrcode.Estimate(s, opts...)
+		require.NoError(t, err)
+		return est.Version
+	}
+
+	maxVersion := version(testContact.MeCard())
+	require.Less(t, maxVersion, version(testContact.VCard(VCard30)))
+	s, err = testContact.Fit(VCard30, maxVersion, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, testContact.MeCard(), s)
+
+	s, err = testContact.Fit(VCard30, maxVersion-1, opts...)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(s, "BEGIN:VCARD"))
+	assert.LessOrEqual(t, version(s), maxVersion-1)
+	got, err := ParseVCard(s)
+	require.NoError(t, err)
+	assert.Equal(t, "Sean Owen", got.FormattedName)
+	assert.Empty(t, got.Note)
+
+	_, err = testContact.Fit(VCard30, 1, opts...)
+	var tooLong *qrcode.DataTooLongError
+	assert.ErrorAs(t, err, &tooLong)
+}
+
+func Test_Contact_QRCode(t *testing.T) {
+	qrc, err := qrcode.New(testContact.VCard(VCard40))
+	require.NoError(t, err)
+
+	result, err := qrcode.Decode(qrc.Matrix())
+	require.NoError(t, err)
+	got, err := ParseVCard(result.Text)
+	require.NoError(t, err)
+	assert.Equal(t, testContact.Emails, got.Emails)
+}
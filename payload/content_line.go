@@ -0,0 +1,112 @@
+package payload
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// contentLineLimit is the maximum octets of a content line without CRLF, which
+// is shared by vCard (RFC 6350) and iCalendar (RFC 5545).
+const contentLineLimit = 75
+
+// textEscaper escapes TEXT values of vCard and iCalendar.
+var textEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `;`, `\;`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// unescapeText unescapes a TEXT value of vCard and iCalendar.
+func unescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' || s[i] == 'N' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+// splitText splits a structured value at sep which is not escaped, and
+// unescapes each part.
+func splitText(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, unescapeText(s[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(parts, unescapeText(s[start:]))
+}
+
+// writeContentLine writes line ending with CRLF, and folds it at 75 octets without
+// breaking UTF-8 characters, continuation lines start with a space.
+func writeContentLine(b *strings.Builder, line string) {
+	limit := contentLineLimit
+	for len(line) > limit {
+		n := limit
+		for n > 0 && !utf8.RuneStart(line[n]) {
+			n--
+		}
+		if n == 0 {
+			// no character starts within limit, line is not valid UTF-8.
+			n = limit
+		}
+		b.WriteString(line[:n])
+		b.WriteString("\r\n ")
+		line = line[n:]
+		// the leading space takes one octet.
+		limit = contentLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// unfoldLines splits s into content lines, a line starting with a space or tab
+// continues the previous one. Both CRLF and LF are accepted, empty lines are dropped.
+func unfoldLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(strings.ReplaceAll(s, "\n ", ""), "\n\t", "")
+
+	lines := strings.Split(s, "\n")
+	n := 0
+	for _, line := range lines {
+		if line != "" {
+			lines[n] = line
+			n++
+		}
+	}
+
+	return lines[:n]
+}
+
+// parseContentLine splits "NAME;PARAM=value:value" into upper case name, parameters
+// with upper case keys and value, ok is false if there is no colon.
+func parseContentLine(line string) (name string, params map[string]string, value string, ok bool) {
+	idx := strings.IndexByte(line, ':')
+	if idx < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:idx], ";")
+	params = make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		if k, v, found := strings.Cut(p, "="); found {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+
+	return strings.ToUpper(parts[0]), params, line[idx+1:], true
+}
@@ -0,0 +1,423 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+const (
+	epcServiceTag     = "BCD"
+	epcIdentification = "SCT"
+
+	// epcMaxBytes is the maximum bytes of EPC payload.
+	epcMaxBytes = 331
+	// epcMaxAmount is the maximum amount in cents, 999999999.99 EUR.
+	epcMaxAmount = 99999999999
+)
+
+// EPCVersion is the version of EPC payload.
+type EPCVersion string
+
+const (
+	// EPCVersion1 requires BIC.
+	EPCVersion1 EPCVersion = "001"
+	// EPCVersion2 makes BIC optional within the EEA, which is the default.
+	EPCVersion2 EPCVersion = "002"
+)
+
+// EPCCharset is the character set of EPC payload, which is written as a number
+// from 1 to 8 in order of the constants.
+type EPCCharset int
+
+const (
+	EPCUTF8 EPCCharset = iota + 1
+	EPCISO8859_1
+	EPCISO8859_2
+	EPCISO8859_4
+	EPCISO8859_5
+	EPCISO8859_7
+	EPCISO8859_10
+	EPCISO8859_15
+)
+
+// eci returns ECI assignment number of the character set.
+func (c EPCCharset) eci() (qrcode.ECI, bool) {
+	switch c {
+	case EPCUTF8:
+		return qrcode.ECI_UTF8, true
+	case EPCISO8859_1:
+		return qrcode.ECI_ISO8859_1, true
+	case EPCISO8859_2:
+		return qrcode.ECI_ISO8859_2, true
+	case EPCISO8859_4:
+		return qrcode.ECI_ISO8859_4, true
+	case EPCISO8859_5:
+		return qrcode.ECI_ISO8859_5, true
+	case EPCISO8859_7:
+		return qrcode.ECI_ISO8859_7, true
+	case EPCISO8859_10:
+		return qrcode.ECI_ISO8859_10, true
+	case EPCISO8859_15:
+		return qrcode.ECI_ISO8859_15, true
+	}
+
+	return 0, false
+}
+
+// EPC is a SEPA credit transfer (also known as GiroCode), such as:
+//
+//	BCD
+//	002
+//	1
+//	SCT
+//	COBADEFFXXX
+//	Red Cross
+//	DE89370400440532013000
+//	EUR12.50
+//	CHAR
+//	RF18539007547034
+//
+// reference:
+// - EPC069-12 Quick Response Code: Guidelines to Enable Data Capture for the Initiation of a SEPA Credit Transfer
+type EPC struct {
+	// Version of payload, empty means EPCVersion2.
+	Version EPCVersion
+
+	// Charset character set of payload, zero means EPCUTF8. Other character sets are
+	// single byte, and the payload is transcoded into them by ECI while encoding.
+	Charset EPCCharset
+
+	// BIC of beneficiary bank, 8 or 11 characters, which is required by EPCVersion1.
+	BIC string
+
+	// Name of beneficiary, required, at most 70 characters.
+	Name string
+
+	// IBAN account number of beneficiary, required, spaces are allowed.
+	IBAN string
+
+	// Amount in cents of EUR, zero means the payer enters it.
+	Amount int64
+
+	// Purpose 4 letters purpose code, such as "CHAR" (charity), empty means no purpose.
+	Purpose string
+
+	// Reference structured remittance information, an ISO 11649 creditor reference
+	// such as "RF18539007547034", exclusive with Remittance.
+	Reference string
+
+	// Remittance unstructured remittance information, at most 140 characters,
+	// exclusive with Reference.
+	Remittance string
+
+	// Information beneficiary to originator information, at most 70 characters.
+	Information string
+}
+
+// version returns Version with default one.
+func (p EPC) version() EPCVersion {
+	if p.Version == "" {
+		return EPCVersion2
+	}
+
+	return p.Version
+}
+
+// charset returns Charset with default one.
+func (p EPC) charset() EPCCharset {
+	if p.Charset == 0 {
+		return EPCUTF8
+	}
+
+	return p.Charset
+}
+
+// Validate checks fields by EPC069-12, and check digits of IBAN and creditor reference.
+// Free text fields must not contain control characters such as line breaks.
+func (p EPC) Validate() error {
+	switch p.version() {
+	case EPCVersion1:
+		if p.BIC == "" {
+			return errors.New("payload: EPC BIC is required by version 001")
+		}
+	case EPCVersion2:
+	default:
+		return fmt.Errorf("payload: unknown EPC version: %s", p.Version)
+	}
+	if _, ok := p.charset().eci(); !ok {
+		return fmt.Errorf("payload: unknown EPC character set: %d", p.Charset)
+	}
+	if p.BIC != "" && !validBIC(compact(p.BIC)) {
+		return fmt.Errorf("payload: invalid EPC BIC: %s", p.BIC)
+	}
+
+	if p.Name == "" {
+		return errors.New("payload: EPC beneficiary name is required")
+	}
+	if err := checkText("beneficiary name", p.Name, 70); err != nil {
+		return err
+	}
+	if !validIBAN(compact(p.IBAN)) {
+		return fmt.Errorf("payload: invalid EPC IBAN: %s", p.IBAN)
+	}
+	if p.Amount < 0 || p.Amount > epcMaxAmount {
+		return fmt.Errorf("payload: EPC amount out of range: %d cents", p.Amount)
+	}
+	if p.Purpose != "" && !validPurpose(p.Purpose) {
+		return fmt.Errorf("payload: invalid EPC purpose: %s", p.Purpose)
+	}
+
+	if p.Reference != "" && p.Remittance != "" {
+		return errors.New("payload: EPC reference and remittance are exclusive")
+	}
+	if p.Reference != "" && !validCreditorReference(compact(p.Reference)) {
+		return fmt.Errorf("payload: invalid EPC creditor reference: %s", p.Reference)
+	}
+	if err := checkText("remittance", p.Remittance, 140); err != nil {
+		return err
+	}
+
+	return checkText("information", p.Information, 70)
+}
+
+// String renders the payload, lines are separated by LF and trailing empty lines
+// are omitted. Call Validate before if fields are not trusted.
+func (p EPC) String() string {
+	amount := ""
+	if p.Amount > 0 {
+		amount = fmt.Sprintf("EUR%d.%02d", p.Amount/100, p.Amount%100)
+	}
+
+	lines := []string{
+		epcServiceTag,
+		string(p.version()),
+		strconv.Itoa(int(p.charset())),
+		epcIdentification,
+		compact(p.BIC),
+		p.Name,
+		compact(p.IBAN),
+		amount,
+		p.Purpose,
+		compact(p.Reference),
+		p.Remittance,
+		p.Information,
+	}
+	for lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Build validates the payment and returns the payload with options to encode it,
+// which set error correction level M as EPC069-12 requires, and transcode the
+// payload into the character set by ECI unless it's UTF-8, which is written as it is.
+//
+//	s, opts, err := epc.Build()
+//	qrc, err := qrcode.NewWith(s, opts...)
+func (p EPC) Build() (string, []qrcode.EncodeOption, error) {
+	if err := p.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	s := p.String()
+	// other character sets are single byte.
+	size := utf8.RuneCountInString(s)
+	if p.charset() == EPCUTF8 {
+		size = len(s)
+	}
+	if size > epcMaxBytes {
+		return "", nil, fmt.Errorf("payload: EPC payload is %d bytes, exceeds %d bytes", size, epcMaxBytes)
+	}
+
+	opts := []qrcode.EncodeOption{qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionMedium)}
+	if charset := p.charset(); charset != EPCUTF8 {
+		eci, _ := charset.eci()
+		opts = append(opts, qrcode.WithECITranscoding(eci))
+	}
+	// it fails if any character is out of the character set.
+	if _, err := qrcode.Estimate(s, opts...); err != nil {
+		return "", nil, fmt.Errorf("payload: EPC: %w", err)
+	}
+
+	return s, opts, nil
+}
+
+// ParseEPC parses EPC payload, lines could be separated by LF or CRLF.
+func ParseEPC(s string) (EPC, error) {
+	var p EPC
+
+	s = strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	lines := strings.Split(s, "\n")
+	if len(lines) < 7 || lines[0] != epcServiceTag {
+		return p, fmt.Errorf("payload: %w: EPC must start with %q and have at least 7 lines",
+			ErrInvalidPayload, epcServiceTag)
+	}
+	if len(lines) > 12 {
+		return p, fmt.Errorf("payload: %w: EPC has %d lines, exceeds 12 lines", ErrInvalidPayload, len(lines))
+	}
+	if lines[3] != epcIdentification {
+		return p, fmt.Errorf("payload: %w: EPC unknown identification: %s", ErrInvalidPayload, lines[3])
+	}
+	lines = append(lines, make([]string, 12-len(lines))...)
+
+	charset, err := strconv.Atoi(lines[2])
+	if err != nil {
+		return p, fmt.Errorf("payload: %w: EPC character set: %v", ErrInvalidPayload, err)
+	}
+	amount, err := parseEPCAmount(lines[7])
+	if err != nil {
+		return p, fmt.Errorf("payload: %w: EPC amount: %v", ErrInvalidPayload, err)
+	}
+
+	p = EPC{
+		Version:     EPCVersion(lines[1]),
+		Charset:     EPCCharset(charset),
+		BIC:         lines[4],
+		Name:        lines[5],
+		IBAN:        lines[6],
+		Amount:      amount,
+		Purpose:     lines[8],
+		Reference:   lines[9],
+		Remittance:  lines[10],
+		Information: lines[11],
+	}
+
+	return p, p.Validate()
+}
+
+// parseEPCAmount parses "EUR12.5" into cents, empty means zero.
+func parseEPCAmount(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if !strings.HasPrefix(s, "EUR") {
+		return 0, fmt.Errorf("currency must be EUR: %s", s)
+	}
+
+	units, cents, hasCents := strings.Cut(s[3:], ".")
+	if units == "" || len(cents) > 2 || (hasCents && cents == "") || !isDigits(units) || !isDigits(cents) {
+		return 0, fmt.Errorf("invalid amount: %s", s)
+	}
+	cents += strings.Repeat("0", 2-len(cents))
+
+	// the amount is bounded by Validate, so it's too large if there are too many digits.
+	if len(units) > 9 {
+		return 0, fmt.Errorf("amount out of range: %s", s)
+	}
+	u, _ := strconv.ParseInt(units, 10, 64)
+	c, _ := strconv.ParseInt(cents, 10, 64)
+	return u*100 + c, nil
+}
+
+// checkText checks that free text s has at most n characters and no control
+// characters, since a line break would shift the following fields.
+func checkText(name, s string, n int) error {
+	if cnt := utf8.RuneCountInString(s); cnt > n {
+		return fmt.Errorf("payload: EPC %s has %d characters, exceeds %d", name, cnt, n)
+	}
+	if i := strings.IndexFunc(s, unicode.IsControl); i >= 0 {
+		r, _ := utf8.DecodeRuneInString(s[i:])
+		return fmt.Errorf("payload: EPC %s has control character %q at %d", name, r, i)
+	}
+
+	return nil
+}
+
+// compact removes spaces and converts s into upper case, such as IBAN in print format.
+func compact(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// validBIC checks BIC (ISO 9362): 4 letters of bank, 2 letters of country, 2 letters
+// or digits of location, and optional 3 letters or digits of branch.
+func validBIC(bic string) bool {
+	if len(bic) != 8 && len(bic) != 11 {
+		return false
+	}
+
+	return isUpperLetters(bic[:6]) && isAlphanumeric(bic[6:])
+}
+
+// validIBAN checks IBAN (ISO 13616): 2 letters of country, 2 check digits and at most
+// 30 letters or digits of account, and the check digits by ISO 7064 MOD 97-10.
+func validIBAN(iban string) bool {
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	if !isUpperLetters(iban[:2]) || !isDigits(iban[2:4]) || !isAlphanumeric(iban[4:]) {
+		return false
+	}
+
+	return mod97(iban) == 1
+}
+
+// validCreditorReference checks creditor reference (ISO 11649): "RF", 2 check digits
+// and at most 21 letters or digits, and the check digits by ISO 7064 MOD 97-10.
+func validCreditorReference(ref string) bool {
+	if len(ref) < 5 || len(ref) > 25 {
+		return false
+	}
+	if ref[:2] != "RF" || !isDigits(ref[2:4]) || !isAlphanumeric(ref[4:]) {
+		return false
+	}
+
+	return mod97(ref) == 1
+}
+
+// validPurpose checks purpose code (ISO 20022 ExternalPurpose1Code), 4 letters.
+func validPurpose(purpose string) bool {
+	return len(purpose) == 4 && isUpperLetters(purpose)
+}
+
+// mod97 moves the first 4 characters of s to the end, converts letters into numbers
+// (A=10, B=11, ..., Z=35), and returns the remainder of the number divided by 97.
+// s must consist of upper case letters and digits.
+func mod97(s string) int {
+	rem := 0
+	for _, c := range s[4:] + s[:4] {
+		if c >= 'A' && c <= 'Z' {
+			rem = (rem*100 + int(c-'A') + 10) % 97
+			continue
+		}
+		rem = (rem*10 + int(c-'0')) % 97
+	}
+
+	return rem
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isUpperLetters(s string) bool {
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isAlphanumeric(s string) bool {
+	for _, c := range s {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
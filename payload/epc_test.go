@@ -0,0 +1,181 @@
+package payload
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEPC = EPC{
+	Version:   EPCVersion2,
+	Charset:   EPCUTF8,
+	BIC:       "COBADEFFXXX",
+	Name:      "Rotes Kreuz e.V.",
+	IBAN:      "DE89370400440532013000",
+	Amount:    1250,
+	Purpose:   "CHAR",
+	Reference: "RF18539007547034",
+}
+
+func Test_EPC_String(t *testing.T) {
+	want := "BCD\n002\n1\nSCT\nCOBADEFFXXX\nRotes Kreuz e.V.\nDE89370400440532013000\nEUR12.50\nCHAR\nRF18539007547034"
+	require.NoError(t, testEPC.Validate())
+	assert.Equal(t, want, testEPC.String())
+
+	got, err := ParseEPC(want)
+	require.NoError(t, err)
+	assert.Equal(t, testEPC, got)
+
+	// defaults, print format of IBAN, and trailing empty lines are omitted.
+	p := EPC{Name: "Jane Doe", IBAN: "de89 3704 0044 0532 0130 00", Remittance: "Invoice 2026-001"}
+	require.NoError(t, p.Validate())
+	assert.Equal(t, "BCD\n002\n1\nSCT\n\nJane Doe\nDE89370400440532013000\n\n\n\nInvoice 2026-001", p.String())
+
+	p = EPC{Name: "Jane Doe", IBAN: "DE89370400440532013000"}
+	assert.Equal(t, "BCD\n002\n1\nSCT\n\nJane Doe\nDE89370400440532013000", p.String())
+}
+
+func Test_EPC_Validate(t *testing.T) {
+	invalid := map[string]func(p *EPC){
+		"unknown version":       func(p *EPC) { p.Version = "003" },
+		"BIC required by 001":   func(p *EPC) { p.Version, p.BIC = EPCVersion1, "" },
+		"unknown charset":       func(p *EPC) { p.Charset = 9 },
+		"invalid BIC":           func(p *EPC) { p.BIC = "COBA12FF" },
+		"name required":         func(p *EPC) { p.Name = "" },
+		"name too long":         func(p *EPC) { p.Name = strings.Repeat("ä", 71) },
+		"IBAN check digits":     func(p *EPC) { p.IBAN = "DE88370400440532013000" },
+		"IBAN too short":        func(p *EPC) { p.IBAN = "DE8937040044" },
+		"IBAN invalid":          func(p *EPC) { p.IBAN = "DE89-370400440532013000" },
+		"negative amount":       func(p *EPC) { p.Amount = -1 },
+		"amount too large":      func(p *EPC) { p.Amount = 100000000000 },
+		"invalid purpose":       func(p *EPC) { p.Purpose = "CHARITY" },
+		"reference check digit": func(p *EPC) { p.Reference = "RF19539007547034" },
+		"exclusive remittance":  func(p *EPC) { p.Remittance = "Invoice" },
+		"remittance too long": func(p *EPC) {
+			p.Reference, p.Remittance = "", strings.Repeat("x", 141)
+		},
+		"information too long": func(p *EPC) { p.Information = strings.Repeat("x", 71) },
+		"LF in name":           func(p *EPC) { p.Name = "Red\nCross" },
+		"CR in name":           func(p *EPC) { p.Name = "Red\rCross" },
+		"LF in remittance":     func(p *EPC) { p.Reference, p.Remittance = "", "a\nb\nc" },
+		"tab in remittance":    func(p *EPC) { p.Reference, p.Remittance = "", "a\tb" },
+		"CRLF in information":  func(p *EPC) { p.Information = "a\r\nb" },
+		"C1 in information":    func(p *EPC) { p.Information = "a\u0085b" },
+	}
+	for name, modify := range invalid {
+		p := testEPC
+		modify(&p)
+		assert.Error(t, p.Validate(), name)
+	}
+
+	p := testEPC
+	p.Version, p.Amount = EPCVersion1, 99999999999
+	assert.NoError(t, p.Validate())
+	p.BIC = "COBADEFF"
+	assert.NoError(t, p.Validate())
+}
+
+func Test_mod97(t *testing.T) {
+	for _, iban := range []string{
+		"DE89370400440532013000",
+		"GB82WEST12345698765432",
+		"FR1420041010050500013M02606",
+		"NL91ABNA0417164300",
+	} {
+		assert.True(t, validIBAN(iban), iban)
+	}
+	assert.False(t, validIBAN("GB82WEST12345698765433"))
+	assert.True(t, validCreditorReference("RF18000000000539007547034"))
+	assert.False(t, validCreditorReference("RF18000000000539007547035"))
+}
+
+func Test_EPC_Build(t *testing.T) {
+	s, opts, err := testEPC.Build()
+	require.NoError(t, err)
+	assert.Equal(t, testEPC.String(), s)
+
+	qrc, err := qrcode.NewWith(s, opts...)
+	require.NoError(t, err)
+	result, err := qrcode.Decode(qrc.Matrix())
+	require.NoError(t, err)
+	assert.Equal(t, qrcode.ErrorCorrectionMedium, result.ECLevel)
+	assert.Nil(t, result.ECI)
+	got, err := ParseEPC(result.Text)
+	require.NoError(t, err)
+	assert.Equal(t, testEPC, got)
+
+	// the payload is transcoded into the character set and declared by ECI.
+	p := testEPC
+	p.Charset, p.Name = EPCISO8859_15, "Müller €"
+	s, opts, err = p.Build()
+	require.NoError(t, err)
+	qrc, err = qrcode.NewWith(s, opts...)
+	require.NoError(t, err)
+	result, err = qrcode.Decode(qrc.Matrix())
+	require.NoError(t, err)
+	require.NotNil(t, result.ECI)
+	assert.Equal(t, qrcode.ECI_ISO8859_15, *result.ECI)
+	got, err = ParseEPC(result.Text)
+	require.NoError(t, err)
+	assert.Equal(t, p, got)
+
+	// "€" is out of ISO 8859-1.
+	p.Charset = EPCISO8859_1
+	_, _, err = p.Build()
+	assert.Error(t, err)
+}
+
+func Test_EPC_Build_Limit(t *testing.T) {
+	p := testEPC
+	p.Reference = ""
+	p.Name = strings.Repeat("x", 70)
+	p.Remittance = strings.Repeat("x", 140)
+	_, _, err := p.Build()
+	require.NoError(t, err)
+
+	// every field is in range, but the payload exceeds 331 bytes.
+	p.Information = strings.Repeat("x", 70)
+	require.NoError(t, p.Validate())
+	_, _, err = p.Build()
+	assert.Error(t, err)
+
+	// each "ä" takes 2 bytes in UTF-8, but 1 byte in ISO 8859-1.
+	p.Information = ""
+	p.Remittance = strings.Repeat("ä", 140)
+	_, _, err = p.Build()
+	assert.Error(t, err)
+	p.Charset = EPCISO8859_1
+	_, _, err = p.Build()
+	assert.NoError(t, err)
+}
+
+func Test_ParseEPC(t *testing.T) {
+	// CRLF line endings, a trailing line ending, and amount without cents.
+	got, err := ParseEPC("BCD\r\n001\r\n2\r\nSCT\r\nCOBADEFF\r\nJane Doe\r\nDE89370400440532013000\r\nEUR5\r\n")
+	require.NoError(t, err)
+	assert.Equal(t, EPC{Version: EPCVersion1, Charset: EPCISO8859_1, BIC: "COBADEFF", Name: "Jane Doe",
+		IBAN: "DE89370400440532013000", Amount: 500}, got)
+
+	got, err = ParseEPC("BCD\n002\n1\nSCT\n\nJane Doe\nDE89370400440532013000\nEUR0.5")
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), got.Amount)
+
+	for _, s := range []string{
+		"BCD\n002\n1\nSCT\n\nJane Doe",
+		"WIFI:S:home;;",
+		"BCD\n002\n1\nINST\n\nJane Doe\nDE89370400440532013000",
+		"BCD\n002\nx\nSCT\n\nJane Doe\nDE89370400440532013000",
+		"BCD\n002\n1\nSCT\n\nJane Doe\nDE89370400440532013000\nUSD5",
+		"BCD\n002\n1\nSCT\n\nJane Doe\nDE89370400440532013000\nEUR5.123",
+		"BCD\n002\n1\nSCT\n\nJane Doe\nDE89370400440532013000\nEUR.5",
+		"BCD\n002\n1\nSCT\n\nJane Doe\nDE89370400440532013000\nEUR1234567890",
+		"BCD\n002\n1\nSCT\n\nJane Doe\nDE89370400440532013000\n\n\n\n\n\nextra",
+	} {
+		_, err = ParseEPC(s)
+		assert.ErrorIs(t, err, ErrInvalidPayload, s)
+	}
+}
@@ -0,0 +1,315 @@
+package payload
+
+import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// iCalendarProdID identifies the product which created the iCalendar object.
+	iCalendarProdID = "-//yeqown//go-qrcode//EN"
+
+	iCalendarUTCLayout   = "20060102T150405Z"
+	iCalendarLocalLayout = "20060102T150405"
+)
+
+// Event is an iCalendar event (VEVENT), such as:
+//
+//	BEGIN:VCALENDAR
+//	VERSION:2.0
+//	PRODID:-//yeqown//go-qrcode//EN
+//	BEGIN:VEVENT
+//	UID:...
+//	DTSTAMP:20261018T090000Z
+//	DTSTART:20261101T180000Z
+//	SUMMARY:Go meetup
+//	END:VEVENT
+//	END:VCALENDAR
+//
+// reference:
+// - https://www.rfc-editor.org/rfc/rfc5545
+type Event struct {
+	// UID unique identifier of event, empty means it's derived from Summary, Start and Location.
+	UID string
+
+	// Summary title of event, required.
+	Summary string
+
+	// Location of event.
+	Location string
+
+	// Description any text, which may contain new lines.
+	Description string
+
+	// URL of event.
+	URL string
+
+	// Start of event, required. Times in UTC are written in UTC form (with "Z"), times in
+	// other locations are written with TZID of the location and a VTIMEZONE component
+	// which declares the offset at Start.
+	Start time.Time
+
+	// End of event, zero means no end. It's written in the same form as Start.
+	End time.Time
+
+	// Stamp DTSTAMP the time when the event was created, zero means now.
+	Stamp time.Time
+}
+
+// Validate checks required fields of event.
+func (e Event) Validate() error {
+	if e.Summary == "" {
+		return errors.New("payload: event summary is required")
+	}
+	if e.Start.IsZero() {
+		return errors.New("payload: event start is required")
+	}
+	if !e.End.IsZero() && e.End.Before(e.Start) {
+		return errors.New("payload: event ends before it starts")
+	}
+
+	return nil
+}
+
+// uid returns UID with default one.
+func (e Event) uid() string {
+	if e.UID != "" {
+		return e.UID
+	}
+
+	sum := sha1.Sum([]byte(e.Summary + "\n" + e.Start.UTC().Format(iCalendarUTCLayout) + "\n" + e.Location))
+	return fmt.Sprintf("%x@go-qrcode", sum[:10])
+}
+
+// zone returns TZID and the offset of location of Start, ok is false if Start and End
+// should be written in UTC, such as the location is UTC, Local or unnamed (parsed from
+// RFC 3339 offset) whose name is not a TZID, or the locations or offsets of Start and End differ.
+func (e Event) zone() (tzid string, offset int, ok bool) {
+	loc := e.Start.Location()
+	if loc == time.UTC || loc == time.Local || loc.String() == "" {
+		return "", 0, false
+	}
+
+	_, offset = e.Start.Zone()
+	if !e.End.IsZero() {
+		if _, endOffset := e.End.Zone(); endOffset != offset || e.End.Location().String() != loc.String() {
+			return "", 0, false
+		}
+	}
+
+	return loc.String(), offset, true
+}
+
+// String renders the event as an iCalendar object, lines end with CRLF and are
+// folded at 75 octets. Call Validate before if fields are not trusted.
+func (e Event) String() string {
+	stamp := e.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	tzid, offset, local := e.zone()
+
+	// dateTime returns the property of t in UTC or in local time with TZID.
+	dateTime := func(name string, t time.Time) string {
+		if !local {
+			return name + ":" + t.UTC().Format(iCalendarUTCLayout)
+		}
+		return name + ";TZID=" + quoteParam(tzid) + ":" + t.In(e.Start.Location()).Format(iCalendarLocalLayout)
+	}
+
+	var b strings.Builder
+	writeContentLine(&b, "BEGIN:VCALENDAR")
+	writeContentLine(&b, "VERSION:2.0")
+	writeContentLine(&b, "PRODID:"+iCalendarProdID)
+	if local {
+		// the offset is fixed from the beginning of time, which is enough for this event.
+		writeContentLine(&b, "BEGIN:VTIMEZONE")
+		writeContentLine(&b, "TZID:"+escapeText(tzid))
+		writeContentLine(&b, "BEGIN:STANDARD")
+		writeContentLine(&b, "DTSTART:19700101T000000")
+		writeContentLine(&b, "TZOFFSETFROM:"+formatUTCOffset(offset))
+		writeContentLine(&b, "TZOFFSETTO:"+formatUTCOffset(offset))
+		writeContentLine(&b, "END:STANDARD")
+		writeContentLine(&b, "END:VTIMEZONE")
+	}
+	writeContentLine(&b, "BEGIN:VEVENT")
+	writeContentLine(&b, "UID:"+escapeText(e.uid()))
+	writeContentLine(&b, "DTSTAMP:"+stamp.UTC().Format(iCalendarUTCLayout))
+	writeContentLine(&b, dateTime("DTSTART", e.Start))
+	if !e.End.IsZero() {
+		writeContentLine(&b, dateTime("DTEND", e.End))
+	}
+	writeContentLine(&b, "SUMMARY:"+escapeText(e.Summary))
+	if e.Location != "" {
+		writeContentLine(&b, "LOCATION:"+escapeText(e.Location))
+	}
+	if e.Description != "" {
+		writeContentLine(&b, "DESCRIPTION:"+escapeText(e.Description))
+	}
+	if e.URL != "" {
+		// URL is a URI value, which is not escaped.
+		writeContentLine(&b, "URL:"+e.URL)
+	}
+	writeContentLine(&b, "END:VEVENT")
+	writeContentLine(&b, "END:VCALENDAR")
+
+	return b.String()
+}
+
+// ParseEvent parses the first VEVENT in s, the VCALENDAR wrapper is optional.
+// TZID is resolved by the IANA time zone database, or by the offset declared in
+// VTIMEZONE if it's unknown. Unknown properties and parameters are ignored.
+func ParseEvent(s string) (Event, error) {
+	var (
+		e       Event
+		inEvent bool
+		ended   bool
+		inZone  bool
+		tzid    string
+		offsets = make(map[string]int) // offsets declared by VTIMEZONE.
+		times   = make(map[string]dateTimeProperty)
+	)
+
+loop:
+	for _, line := range unfoldLines(s) {
+		name, params, value, ok := parseContentLine(line)
+		if !ok {
+			return e, fmt.Errorf("payload: %w: iCalendar: invalid line %q", ErrInvalidPayload, line)
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent = true
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if !inEvent {
+				return e, fmt.Errorf("payload: %w: iCalendar: END:VEVENT without BEGIN", ErrInvalidPayload)
+			}
+			ended = true
+			break loop
+		case name == "BEGIN" && strings.EqualFold(value, "VTIMEZONE"):
+			inZone = true
+		case name == "END" && strings.EqualFold(value, "VTIMEZONE"):
+			inZone = false
+		case inZone && name == "TZID":
+			tzid = unescapeText(value)
+		case inZone && name == "TZOFFSETTO":
+			offset, err := parseUTCOffset(value)
+			if err != nil {
+				return e, fmt.Errorf("payload: %w: iCalendar: %v", ErrInvalidPayload, err)
+			}
+			offsets[tzid] = offset
+		case inEvent:
+			switch name {
+			case "UID":
+				e.UID = unescapeText(value)
+			case "SUMMARY":
+				e.Summary = unescapeText(value)
+			case "LOCATION":
+				e.Location = unescapeText(value)
+			case "DESCRIPTION":
+				e.Description = unescapeText(value)
+			case "URL":
+				e.URL = value
+			case "DTSTAMP", "DTSTART", "DTEND":
+				times[name] = dateTimeProperty{params: params, value: value}
+			}
+		}
+	}
+	if !ended {
+		return e, fmt.Errorf("payload: %w: iCalendar: no complete VEVENT", ErrInvalidPayload)
+	}
+
+	for name, dst := range map[string]*time.Time{"DTSTAMP": &e.Stamp, "DTSTART": &e.Start, "DTEND": &e.End} {
+		prop, ok := times[name]
+		if !ok {
+			continue
+		}
+		t, err := parseDateTime(prop, offsets)
+		if err != nil {
+			return e, fmt.Errorf("payload: %w: iCalendar: %s: %v", ErrInvalidPayload, name, err)
+		}
+		*dst = t
+	}
+
+	return e, e.Validate()
+}
+
+// dateTimeProperty is a DATE-TIME property such as DTSTART.
+type dateTimeProperty struct {
+	params map[string]string
+	value  string
+}
+
+// parseDateTime parses DATE-TIME value in UTC form, or local time in TZID parameter.
+func parseDateTime(prop dateTimeProperty, offsets map[string]int) (time.Time, error) {
+	value := prop.value
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(iCalendarUTCLayout, value)
+	}
+	tzid, ok := prop.params["TZID"]
+	if !ok {
+		// floating time is treated as local time.
+		return time.ParseInLocation(iCalendarLocalLayout, value, time.Local)
+	}
+	if tzid == "" {
+		return time.Time{}, errors.New("empty TZID")
+	}
+
+	loc, err := time.LoadLocation(tzid)
+	if err != nil {
+		offset, ok := offsets[tzid]
+		if !ok {
+			return time.Time{}, fmt.Errorf("unknown TZID %q", tzid)
+		}
+		loc = time.FixedZone(tzid, offset)
+	}
+
+	return time.ParseInLocation(iCalendarLocalLayout, value, loc)
+}
+
+// formatUTCOffset formats offset in seconds as "+hhmm" or "+hhmmss".
+func formatUTCOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	if s := offset % 60; s != 0 {
+		return fmt.Sprintf("%c%02d%02d%02d", sign, offset/3600, offset/60%60, s)
+	}
+
+	return fmt.Sprintf("%c%02d%02d", sign, offset/3600, offset/60%60)
+}
+
+// parseUTCOffset parses "+hhmm" or "+hhmmss" into seconds.
+func parseUTCOffset(s string) (int, error) {
+	var h, m, sec int
+	if len(s) != 5 && len(s) != 7 || (s[0] != '+' && s[0] != '-') {
+		return 0, fmt.Errorf("invalid UTC offset %q", s)
+	}
+	if _, err := fmt.Sscanf(s[1:5], "%02d%02d", &h, &m); err != nil {
+		return 0, fmt.Errorf("invalid UTC offset %q", s)
+	}
+	if len(s) == 7 {
+		if _, err := fmt.Sscanf(s[5:], "%02d", &sec); err != nil {
+			return 0, fmt.Errorf("invalid UTC offset %q", s)
+		}
+	}
+
+	offset := h*3600 + m*60 + sec
+	if s[0] == '-' {
+		offset = -offset
+	}
+	return offset, nil
+}
+
+// quoteParam quotes parameter value which contains ":", ";" or ",".
+func quoteParam(s string) string {
+	if strings.ContainsAny(s, ":;,") {
+		return `"` + s + `"`
+	}
+
+	return s
+}
@@ -0,0 +1,172 @@
+package payload
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEvent = Event{
+	UID:         "meetup-2026-11@example.com",
+	Summary:     "Go meetup; Q&A, drinks",
+	Location:    "Room 1, 2nd Floor",
+	Description: "Agenda:\n1. QR codes\n2. Back\\slash",
+	URL:         "https://example.com/meetup?id=1,2",
+	Start:       time.Date(2026, 11, 1, 18, 0, 0, 0, time.UTC),
+	End:         time.Date(2026, 11, 1, 20, 30, 0, 0, time.UTC),
+	Stamp:       time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+}
+
+func Test_Event_String(t *testing.T) {
+	want := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//yeqown//go-qrcode//EN\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:meetup-2026-11@example.com\r\n" +
+		"DTSTAMP:20261018T090000Z\r\n" +
+		"DTSTART:20261101T180000Z\r\n" +
+		"DTEND:20261101T203000Z\r\n" +
+		"SUMMARY:Go meetup\\; Q&A\\, drinks\r\n" +
+		"LOCATION:Room 1\\, 2nd Floor\r\n" +
+		"DESCRIPTION:Agenda:\\n1. QR codes\\n2. Back\\\\slash\r\n" +
+		"URL:https://example.com/meetup?id=1,2\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	require.NoError(t, testEvent.Validate())
+	assert.Equal(t, want, testEvent.String())
+
+	got, err := ParseEvent(want)
+	require.NoError(t, err)
+	assert.Equal(t, testEvent, got)
+}
+
+func Test_Event_String_Defaults(t *testing.T) {
+	e := Event{Summary: "Standup", Start: time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC)}
+	s := e.String()
+	assert.NotContains(t, s, "DTEND")
+	assert.NotContains(t, s, "LOCATION")
+
+	got, err := ParseEvent(s)
+	require.NoError(t, err)
+	// UID is derived from fields, so it's stable.
+	assert.Equal(t, e.uid(), got.UID)
+	assert.True(t, strings.HasSuffix(got.UID, "@go-qrcode"))
+	assert.Equal(t, got.UID, Event{Summary: "Standup", Start: e.Start}.uid())
+	assert.NotEqual(t, got.UID, Event{Summary: "Retro", Start: e.Start}.uid())
+	assert.WithinDuration(t, time.Now(), got.Stamp, time.Minute)
+}
+
+func Test_Event_TimeZone(t *testing.T) {
+	loc := time.FixedZone("Asia/Shanghai", 8*3600)
+	e := testEvent
+	e.Start = time.Date(2026, 11, 2, 2, 0, 0, 0, loc)
+	e.End = time.Date(2026, 11, 2, 4, 30, 0, 0, loc)
+	s := e.String()
+	assert.Contains(t, s, "BEGIN:VTIMEZONE\r\nTZID:Asia/Shanghai\r\n")
+	assert.Contains(t, s, "TZOFFSETTO:+0800\r\n")
+	assert.Contains(t, s, "DTSTART;TZID=Asia/Shanghai:20261102T020000\r\n")
+	assert.Contains(t, s, "DTEND;TZID=Asia/Shanghai:20261102T043000\r\n")
+
+	got, err := ParseEvent(s)
+	require.NoError(t, err)
+	assert.True(t, e.Start.Equal(got.Start))
+	assert.True(t, e.End.Equal(got.End))
+	_, offset := got.Start.Zone()
+	assert.Equal(t, 8*3600, offset)
+
+	// unknown TZID is resolved by the offset declared in VTIMEZONE.
+	e.Start = time.Date(2026, 11, 2, 2, 0, 0, 0, time.FixedZone("Mars/Base", -(5*3600+30*60)))
+	e.End = time.Time{}
+	s = e.String()
+	assert.Contains(t, s, "TZOFFSETTO:-0530\r\n")
+	got, err = ParseEvent(s)
+	require.NoError(t, err)
+	assert.True(t, e.Start.Equal(got.Start))
+
+	// locations of start and end differ, so they are written in UTC.
+	e.End = time.Date(2026, 11, 2, 10, 0, 0, 0, time.FixedZone("Moon/Base", 0))
+	s = e.String()
+	assert.NotContains(t, s, "VTIMEZONE")
+	assert.Contains(t, s, "DTSTART:20261102T073000Z\r\n")
+	assert.Contains(t, s, "DTEND:20261102T100000Z\r\n")
+
+	// unnamed location parsed from RFC 3339 offset has no TZID, so it's written in UTC.
+	e.Start, _ = time.Parse(time.RFC3339, "2026-11-01T18:00:00+01:00")
+	e.End, _ = time.Parse(time.RFC3339, "2026-11-01T20:00:00+01:00")
+	s = e.String()
+	assert.NotContains(t, s, "TZID")
+	assert.Contains(t, s, "DTSTART:20261101T170000Z\r\n")
+	got, err = ParseEvent(s)
+	require.NoError(t, err)
+	assert.True(t, e.Start.Equal(got.Start))
+	assert.True(t, e.End.Equal(got.End))
+
+	// offsets of start and end differ in a location with daylight saving time.
+	if ny, err := time.LoadLocation("America/New_York"); err == nil {
+		e.Start = time.Date(2026, 10, 31, 12, 0, 0, 0, ny)
+		e.End = time.Date(2026, 11, 2, 12, 0, 0, 0, ny)
+		assert.NotContains(t, e.String(), "VTIMEZONE")
+	}
+}
+
+func Test_Event_Folding(t *testing.T) {
+	e := testEvent
+	e.Description = strings.Repeat("長い説明, ", 30)
+	s := e.String()
+
+	lines := strings.Split(strings.TrimSuffix(s, "\r\n"), "\r\n")
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, strings.ToValidUTF8(line, "?") == line, "line breaks UTF-8 character: %q", line)
+	}
+
+	got, err := ParseEvent(s)
+	require.NoError(t, err)
+	assert.Equal(t, e.Description, got.Description)
+}
+
+func Test_Event_Validate(t *testing.T) {
+	start := time.Date(2026, 11, 1, 18, 0, 0, 0, time.UTC)
+	assert.Error(t, Event{Start: start}.Validate())
+	assert.Error(t, Event{Summary: "Meetup"}.Validate())
+	assert.Error(t, Event{Summary: "Meetup", Start: start, End: start.Add(-time.Hour)}.Validate())
+	assert.NoError(t, Event{Summary: "Meetup", Start: start, End: start}.Validate())
+}
+
+func Test_ParseEvent(t *testing.T) {
+	// no VCALENDAR wrapper, LF line endings, folding and unknown properties.
+	got, err := ParseEvent("BEGIN:VEVENT\nsummary:Lunch\n  break\nDTSTART:20261101T120000Z\nRRULE:FREQ=DAILY\nEND:VEVENT\n")
+	require.NoError(t, err)
+	assert.Equal(t, Event{Summary: "Lunch break", Start: time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)}, got)
+
+	for _, s := range []string{
+		"BEGIN:VEVENT\r\nSUMMARY:Lunch\r\nDTSTART:20261101T120000Z\r\n",
+		"SUMMARY:Lunch\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nSUMMARY Lunch\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nSUMMARY:Lunch\r\nDTSTART:2026-11-01\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nSUMMARY:Lunch\r\nDTSTART;TZID=Mars/Base:20261101T120000\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nSUMMARY:Lunch\r\nDTSTART;TZID=:20261101T180000\r\nEND:VEVENT\r\n",
+	} {
+		_, err = ParseEvent(s)
+		assert.ErrorIs(t, err, ErrInvalidPayload, s)
+	}
+
+	_, err = ParseEvent("BEGIN:VEVENT\r\nSUMMARY:Lunch\r\nEND:VEVENT\r\n")
+	assert.Error(t, err)
+}
+
+func Test_Event_QRCode(t *testing.T) {
+	qrc, err := qrcode.New(testEvent.String())
+	require.NoError(t, err)
+
+	result, err := qrcode.Decode(qrc.Matrix())
+	require.NoError(t, err)
+	got, err := ParseEvent(result.Text)
+	require.NoError(t, err)
+	assert.Equal(t, testEvent, got)
+}
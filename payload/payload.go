@@ -0,0 +1,104 @@
+// Package payload builds and parses structured texts which are recognized by most
+// scanners, such as Wi-Fi credentials. Each payload renders itself by String, so it
+// could be fed into qrcode.New directly:
+//
+//	wifi := payload.WiFi{SSID: "guest", Password: "p@ss;word", Auth: payload.WiFiWPA}
+//	qrc, err := qrcode.New(wifi.String())
+package payload
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidPayload means the text could not be parsed as the payload.
+var ErrInvalidPayload = errors.New("invalid payload")
+
+// fieldEscaper escapes special characters of "KEY:value;" fields, which are used by
+// Wi-Fi and MeCard payloads.
+var fieldEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`, `"`, `\"`)
+
+// writeField writes "key:value;" with value escaped, empty value is skipped.
+func writeField(b *strings.Builder, key, value string) {
+	if value == "" {
+		return
+	}
+
+	b.WriteString(key)
+	b.WriteByte(':')
+	_, _ = fieldEscaper.WriteString(b, value)
+	b.WriteByte(';')
+}
+
+// field is a "key:value" pair with value unescaped, raw is the value as it is
+// to split structured values such as the name of MeCard.
+type field struct {
+	key, value, raw string
+}
+
+// parseFields splits "KEY:value;KEY:value;;" into fields, a backslash escapes the
+// following character. The text must end with an empty field (";;").
+func parseFields(s string) ([]field, error) {
+	var (
+		fields []field
+		cur    strings.Builder
+		raw    strings.Builder
+		key    string
+		hasKey bool
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			raw.WriteString(s[i : i+2])
+			i++
+			cur.WriteByte(s[i])
+		case c == ':' && !hasKey:
+			key, hasKey = cur.String(), true
+			cur.Reset()
+			raw.Reset()
+		case c == ';':
+			if !hasKey && cur.Len() == 0 {
+				// the empty field terminates payload.
+				if i != len(s)-1 {
+					return nil, errors.New("unexpected text after terminator")
+				}
+				return fields, nil
+			}
+			if !hasKey {
+				return nil, errors.New("field without key: " + cur.String())
+			}
+			fields = append(fields, field{key: key, value: cur.String(), raw: raw.String()})
+			cur.Reset()
+			raw.Reset()
+			hasKey = false
+		default:
+			cur.WriteByte(c)
+			raw.WriteByte(c)
+		}
+	}
+
+	return nil, errors.New("missing terminator")
+}
+
+// splitEscaped splits s at sep which is not escaped by a backslash, and unescapes each part.
+func splitEscaped(s string, sep byte) []string {
+	var (
+		parts []string
+		cur   strings.Builder
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			cur.WriteByte(s[i])
+		case c == sep:
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+
+	return append(parts, cur.String())
+}
@@ -0,0 +1,157 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WiFiAuth is the authentication type of Wi-Fi network.
+type WiFiAuth string
+
+const (
+	// WiFiNoPass open network without password.
+	WiFiNoPass WiFiAuth = "nopass"
+	// WiFiWEP network protected by WEP.
+	WiFiWEP WiFiAuth = "WEP"
+	// WiFiWPA network protected by WPA, WPA2 or WPA3 personal.
+	WiFiWPA WiFiAuth = "WPA"
+	// WiFiWPA2EAP network protected by WPA2 enterprise, EAP fields are required.
+	WiFiWPA2EAP WiFiAuth = "WPA2-EAP"
+)
+
+const wifiPrefix = "WIFI:"
+
+// WiFi is the credentials of Wi-Fi network, such as:
+//
+//	WIFI:T:WPA;S:guest;P:p@ss\;word;;
+//
+// reference:
+// - https://github.com/zxing/zxing/wiki/Barcode-Contents#wi-fi-network-config-android-ios-11
+type WiFi struct {
+	// SSID network name, required.
+	SSID string
+
+	// Password of network, it's empty for WiFiNoPass.
+	Password string
+
+	// Auth authentication type, empty means WiFiNoPass if Password is empty, otherwise WiFiWPA.
+	Auth WiFiAuth
+
+	// Hidden is true if the network doesn't broadcast SSID.
+	Hidden bool
+
+	// EAPMethod EAP method for WiFiWPA2EAP, such as "PEAP", "TTLS" and "PWD".
+	EAPMethod string
+
+	// Identity EAP identity for WiFiWPA2EAP.
+	Identity string
+
+	// AnonymousIdentity EAP anonymous identity for WiFiWPA2EAP.
+	AnonymousIdentity string
+
+	// Phase2Method EAP phase 2 method for WiFiWPA2EAP, such as "MSCHAPV2".
+	Phase2Method string
+}
+
+// auth returns the authentication type with default one.
+func (w WiFi) auth() WiFiAuth {
+	switch {
+	case w.Auth != "":
+		return w.Auth
+	case w.Password == "":
+		return WiFiNoPass
+	default:
+		return WiFiWPA
+	}
+}
+
+// Validate checks required fields of authentication type.
+func (w WiFi) Validate() error {
+	if w.SSID == "" {
+		return errors.New("payload: Wi-Fi SSID is required")
+	}
+
+	switch auth := w.auth(); auth {
+	case WiFiNoPass:
+		if w.Password != "" {
+			return errors.New("payload: Wi-Fi password is set for open network")
+		}
+	case WiFiWEP, WiFiWPA:
+		if w.Password == "" {
+			return fmt.Errorf("payload: Wi-Fi password is required for %s", auth)
+		}
+	case WiFiWPA2EAP:
+		if w.EAPMethod == "" {
+			return errors.New("payload: Wi-Fi EAP method is required for WPA2-EAP")
+		}
+	default:
+		return fmt.Errorf("payload: unknown Wi-Fi authentication type: %s", auth)
+	}
+
+	return nil
+}
+
+// String renders the escaped payload, call Validate before if fields are not trusted.
+func (w WiFi) String() string {
+	auth := w.auth()
+
+	var b strings.Builder
+	b.WriteString(wifiPrefix)
+	writeField(&b, "T", string(auth))
+	writeField(&b, "S", w.SSID)
+	if auth == WiFiWPA2EAP {
+		writeField(&b, "E", w.EAPMethod)
+		writeField(&b, "A", w.AnonymousIdentity)
+		writeField(&b, "I", w.Identity)
+		writeField(&b, "PH2", w.Phase2Method)
+	}
+	if auth != WiFiNoPass {
+		writeField(&b, "P", w.Password)
+	}
+	if w.Hidden {
+		writeField(&b, "H", "true")
+	}
+	b.WriteByte(';')
+
+	return b.String()
+}
+
+// ParseWiFi parses the payload rendered by WiFi.String or other generators,
+// unknown fields are ignored.
+func ParseWiFi(s string) (WiFi, error) {
+	var w WiFi
+	if !strings.HasPrefix(s, wifiPrefix) {
+		return w, fmt.Errorf("payload: %w: Wi-Fi must start with %q", ErrInvalidPayload, wifiPrefix)
+	}
+
+	fields, err := parseFields(s[len(wifiPrefix):])
+	if err != nil {
+		return w, fmt.Errorf("payload: %w: Wi-Fi: %v", ErrInvalidPayload, err)
+	}
+	for _, f := range fields {
+		switch f.key {
+		case "T":
+			w.Auth = WiFiAuth(f.value)
+		case "S":
+			w.SSID = f.value
+		case "P":
+			w.Password = f.value
+		case "H":
+			w.Hidden = strings.EqualFold(f.value, "true")
+		case "E":
+			w.EAPMethod = f.value
+		case "I":
+			w.Identity = f.value
+		case "A":
+			w.AnonymousIdentity = f.value
+		case "PH2":
+			w.Phase2Method = f.value
+		}
+	}
+	if w.Auth == "" {
+		w.Auth = w.auth()
+	}
+
+	return w, w.Validate()
+}
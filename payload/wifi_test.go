@@ -0,0 +1,101 @@
+package payload
+
+import (
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WiFi_String(t *testing.T) {
+	tests := []struct {
+		name string
+		wifi WiFi
+		want string
+	}{
+		{
+			name: "WPA",
+			wifi: WiFi{SSID: "guest", Password: "p@ss;word", Auth: WiFiWPA},
+			want: `WIFI:T:WPA;S:guest;P:p@ss\;word;;`,
+		},
+		{
+			name: "escaping",
+			wifi: WiFi{SSID: `"cafe":1,2`, Password: `a\b`, Hidden: true},
+			want: `WIFI:T:WPA;S:\"cafe\"\:1\,2;P:a\\b;H:true;;`,
+		},
+		{
+			name: "open network",
+			wifi: WiFi{SSID: "open"},
+			want: `WIFI:T:nopass;S:open;;`,
+		},
+		{
+			name: "WEP",
+			wifi: WiFi{SSID: "old", Password: "0123456789", Auth: WiFiWEP},
+			want: `WIFI:T:WEP;S:old;P:0123456789;;`,
+		},
+		{
+			name: "WPA2-EAP",
+			wifi: WiFi{SSID: "corp", Password: "secret", Auth: WiFiWPA2EAP, EAPMethod: "PEAP",
+				Identity: "alice@corp", AnonymousIdentity: "anon", Phase2Method: "MSCHAPV2"},
+			want: `WIFI:T:WPA2-EAP;S:corp;E:PEAP;A:anon;I:alice@corp;PH2:MSCHAPV2;P:secret;;`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.wifi.Validate())
+			assert.Equal(t, tt.want, tt.wifi.String())
+
+			got, err := ParseWiFi(tt.want)
+			require.NoError(t, err)
+			want := tt.wifi
+			want.Auth = want.auth()
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func Test_WiFi_Validate(t *testing.T) {
+	assert.Error(t, WiFi{Password: "secret"}.Validate())
+	assert.Error(t, WiFi{SSID: "guest", Auth: WiFiWPA}.Validate())
+	assert.Error(t, WiFi{SSID: "guest", Password: "secret", Auth: WiFiNoPass}.Validate())
+	assert.Error(t, WiFi{SSID: "corp", Auth: WiFiWPA2EAP}.Validate())
+	assert.Error(t, WiFi{SSID: "guest", Password: "secret", Auth: "WPA4"}.Validate())
+}
+
+func Test_ParseWiFi(t *testing.T) {
+	// unknown fields are ignored, and fields could be in any order.
+	got, err := ParseWiFi(`WIFI:S:home;R:1;T:WPA;P:pass\:word;H:false;;`)
+	require.NoError(t, err)
+	assert.Equal(t, WiFi{SSID: "home", Password: "pass:word", Auth: WiFiWPA}, got)
+
+	for _, s := range []string{
+		`MECARD:N:Owen;;`,
+		`WIFI:T:WPA;S:home;P:secret;`,
+		`WIFI:T:WPA;S:home;P:secret;;extra`,
+		`WIFI:T:WPA;home;;`,
+		`WIFI:T:WPA;S:home;;`,
+	} {
+		_, err = ParseWiFi(s)
+		assert.Error(t, err, s)
+	}
+	_, err = ParseWiFi(`WIFI:T:WPA;S:home`)
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func Test_WiFi_QRCode(t *testing.T) {
+	wifi := WiFi{SSID: "guest; 5G", Password: `p@ss\word`, Hidden: true}
+	qrc, err := qrcode.New(wifi.String())
+	require.NoError(t, err)
+
+	result, err := qrcode.Decode(qrc.Matrix())
+	require.NoError(t, err)
+
+	got, err := ParseWiFi(result.Text)
+	require.NoError(t, err)
+	assert.Equal(t, wifi.SSID, got.SSID)
+	assert.Equal(t, wifi.Password, got.Password)
+	assert.True(t, got.Hidden)
+}
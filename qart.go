@@ -0,0 +1,282 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"sort"
+	"sync"
+
+	"github.com/yeqown/reedsolomon"
+	"github.com/yeqown/reedsolomon/binary"
+)
+
+// qartOption holds the target image of WithQArt.
+type qartOption struct {
+	// target image, dark pixels are steered to dark modules.
+	target image.Image
+
+	// priority image, brighter pixels are steered first, nil means the
+	// contrast of target is used.
+	priority image.Image
+}
+
+// qartPixel is the target value of a module.
+type qartPixel struct {
+	dark     bool
+	priority int
+}
+
+// sample scales target and priority images to dimension x dimension modules,
+// each module takes the average luminance of pixels it covers.
+func (o *qartOption) sample(dimension int) [][]qartPixel {
+	target := sampleLuma(o.target, dimension)
+	var priority [][]uint8
+	if o.priority != nil {
+		priority = sampleLuma(o.priority, dimension)
+	}
+
+	pixels := make([][]qartPixel, dimension)
+	for x := range pixels {
+		pixels[x] = make([]qartPixel, dimension)
+		for y := range pixels[x] {
+			luma := int(target[x][y])
+			pixels[x][y] = qartPixel{dark: luma < 0x80, priority: abs(luma - 0x80)}
+			if priority != nil {
+				pixels[x][y].priority = int(priority[x][y])
+			}
+		}
+	}
+
+	return pixels
+}
+
+// sampleLuma returns the average luminance of img in each of dimension x dimension cells.
+func sampleLuma(img image.Image, dimension int) [][]uint8 {
+	bound := img.Bounds()
+	cells := make([][]uint8, dimension)
+	for x := range cells {
+		cells[x] = make([]uint8, dimension)
+		x0 := bound.Min.X + x*bound.Dx()/dimension
+		x1 := max(bound.Min.X+(x+1)*bound.Dx()/dimension, x0+1)
+		for y := range cells[x] {
+			y0 := bound.Min.Y + y*bound.Dy()/dimension
+			y1 := max(bound.Min.Y+(y+1)*bound.Dy()/dimension, y0+1)
+
+			sum, n := 0, 0
+			for i := x0; i < x1; i++ {
+				for j := y0; j < y1; j++ {
+					sum += int(color.GrayModel.Convert(img.At(i, j)).(color.Gray).Y)
+					n++
+				}
+			}
+			cells[x][y] = uint8(sum / n)
+		}
+	}
+
+	return cells
+}
+
+// bitVector is a codeword sequence as bits, bit 0 is the most significant bit of first codeword.
+type bitVector []uint64
+
+func newBitVector(codewords []byte) bitVector {
+	v := make(bitVector, (len(codewords)+7)/8)
+	for i, c := range codewords {
+		v[i/8] |= uint64(c) << (56 - 8*(i%8))
+	}
+
+	return v
+}
+
+func (v bitVector) at(i int) bool {
+	return v[i/64]>>(63-i%64)&1 == 1
+}
+
+func (v bitVector) xor(other bitVector) {
+	for i := range v {
+		v[i] ^= other[i]
+	}
+}
+
+// codeword returns the i-th codeword.
+func (v bitVector) codeword(i int) byte {
+	return byte(v[i/8] >> (56 - 8*(i%8)))
+}
+
+// qartBlock is a block to steer.
+type qartBlock struct {
+	// base is the data and EC codewords of block as they are encoded.
+	base bitVector
+
+	// basis is the change of all codewords while flipping each free data bit,
+	// since Reed-Solomon code is linear over GF(2).
+	basis []bitVector
+
+	// locs are positions of each bit of codewords.
+	locs []loc
+
+	numData int
+}
+
+// steer replaces padding codewords of blocks, so that data modules approximate the
+// target image of WithQArt as much as possible. Each block is steered greedily by
+// Gaussian elimination on the changes of free bits, the bits with higher priority
+// are fixed first. The mask pattern which matches target best is chosen and forced.
+func (q *QRCode) steer(dataBlocks []dataBlock) []dataBlock {
+	dimension := q.v.Dimension()
+	pixels := q.encodingOption.QArt.sample(dimension)
+	blocks := q.qartBlocks(dataBlocks)
+
+	patterns := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	if p := q.encodingOption.MaskPattern; p != nil {
+		patterns = []int{*p}
+	}
+
+	var (
+		results = make([][]bitVector, 8)
+		scores  = make([]int, 8)
+		wg      sync.WaitGroup
+	)
+	for _, i := range patterns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			moduloFn := getModuloFunc(maskPatternModulo(i))
+			results[i] = make([]bitVector, len(blocks))
+			for idx, b := range blocks {
+				results[i][idx], scores[i] = b.steer(pixels, moduloFn, scores[i])
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// the lower pattern wins if scores are equal, the same as masking.
+	pattern := patterns[0]
+	for _, i := range patterns[1:] {
+		if scores[i] < scores[pattern] {
+			pattern = i
+		}
+	}
+	q.encodingOption.MaskPattern = &pattern
+
+	for idx, b := range blocks {
+		data := binary.New()
+		for i := 0; i < b.numData; i++ {
+			data.AppendBytes(results[pattern][idx].codeword(i))
+		}
+		dataBlocks[idx].Data = data
+	}
+
+	return dataBlocks
+}
+
+// qartBlocks prepares blocks to steer, only padding codewords after terminator are free.
+func (q *QRCode) qartBlocks(dataBlocks []dataBlock) []*qartBlock {
+	// codewords before firstFree contain header, segments and terminator.
+	capBits := q.v.NumTotalCodewords() * 8
+	fixed := q.dataBits()
+	fixed += min(4, capBits-fixed)
+	firstFree := (fixed + 7) / 8
+
+	blocks := make([]*qartBlock, len(dataBlocks))
+	start := 0
+	for idx, db := range dataBlocks {
+		numData := db.Data.Len() / 8
+		full := reedsolomon.Encode(db.Data, db.NumECBlock).Bytes()
+		blocks[idx] = &qartBlock{
+			base:    newBitVector(full),
+			locs:    make([]loc, len(full)*8),
+			numData: numData,
+		}
+
+		for i := max(firstFree-start, 0); i < numData; i++ {
+			for bit := 0; bit < 8; bit++ {
+				unit := make([]byte, numData)
+				unit[i] = 0x80 >> bit
+				blocks[idx].basis = append(blocks[idx].basis, newBitVector(encodeUnit(unit, db.NumECBlock)))
+			}
+		}
+		start += numData
+	}
+
+	// positions are located by prefilling a fresh matrix, as readCodewords does.
+	dimension := q.v.Dimension()
+	layout := &QRCode{v: q.v, mat: newMatrix(dimension, dimension)}
+	layout.prefillMatrix()
+	bitAt := interleavedBitAt(q.v.Groups)
+	for pos, l := range dataModules(layout.mat, dimension-1, 6) {
+		if m, ok := bitAt(pos); ok {
+			blocks[m.Block].locs[m.Codeword*8+7-m.Bit] = l
+		}
+	}
+
+	return blocks
+}
+
+// encodeUnit returns data codewords followed by numEC EC codewords.
+func encodeUnit(data []byte, numEC int) []byte {
+	bin := binary.New()
+	bin.AppendBytes(data...)
+
+	return reedsolomon.Encode(bin, numEC).Bytes()
+}
+
+// steer returns the codewords of block which match pixels under mask moduloFn best,
+// and adds the priority of mismatched modules to score.
+func (b *qartBlock) steer(pixels [][]qartPixel, moduloFn moduloFunc, score int) (bitVector, int) {
+	// want reports whether bit i should be set, a module is dark if its bit differs from mask.
+	want := func(i int) bool {
+		l := b.locs[i]
+		return pixels[l.X][l.Y].dark != moduloFn(l.X, l.Y)
+	}
+	priority := func(i int) int {
+		l := b.locs[i]
+		return pixels[l.X][l.Y].priority
+	}
+
+	order := make([]int, len(b.locs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return priority(order[i]) > priority(order[j]) })
+
+	cur := append(bitVector(nil), b.base...)
+	basis := make([]bitVector, len(b.basis))
+	for i, v := range b.basis {
+		basis[i] = append(bitVector(nil), v...)
+	}
+
+	for _, i := range order {
+		pivot := -1
+		for j, v := range basis {
+			if v.at(i) {
+				pivot = j
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+
+		// bit i would never be changed by other vectors.
+		pv := basis[pivot]
+		basis = append(basis[:pivot], basis[pivot+1:]...)
+		for _, v := range basis {
+			if v.at(i) {
+				v.xor(pv)
+			}
+		}
+		if cur.at(i) != want(i) {
+			cur.xor(pv)
+		}
+	}
+
+	for i := range b.locs {
+		if cur.at(i) != want(i) {
+			score += priority(i)
+		}
+	}
+
+	return cur, score
+}
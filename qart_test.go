@@ -0,0 +1,110 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const qartText = "https://github.com/yeqown/go-qrcode"
+
+// diskImage draws a dark disk on light background.
+func diskImage(size int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	r := size / 3
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			dx, dy := x-size/2, y-size/2
+			img.SetGray(x, y, color.Gray{Y: 0xff})
+			if dx*dx+dy*dy < r*r {
+				img.SetGray(x, y, color.Gray{})
+			}
+		}
+	}
+
+	return img
+}
+
+// matchRate returns the rate of data modules in rect which match target.
+func matchRate(qrc *QRCode, target image.Image, rect image.Rectangle) float64 {
+	pixels := (&qartOption{target: target}).sample(qrc.mat.Width())
+	matched, total := 0, 0
+	qrc.mat.iter(IterDirection_ROW, func(x, y int, v qrvalue) {
+		if v.qrtype() != QRType_DATA || !image.Pt(x, y).In(rect) {
+			return
+		}
+		total++
+		if v.qrbool() == pixels[x][y].dark {
+			matched++
+		}
+	})
+
+	return float64(matched) / float64(total)
+}
+
+func Test_WithQArt(t *testing.T) {
+	target := diskImage(300)
+	opts := []EncodeOption{WithVersion(10), WithErrorCorrectionLevel(ErrorCorrectionLow)}
+
+	plain, err := NewWith(qartText, opts...)
+	require.NoError(t, err)
+	qrc, err := NewWith(qartText, append(opts, WithQArt(target, nil), WithVerify())...)
+	require.NoError(t, err)
+
+	got, err := Decode(*qrc.mat)
+	require.NoError(t, err)
+	assert.Equal(t, qartText, got.Text)
+	assert.Equal(t, 10, got.Version)
+	assert.Equal(t, 0, got.CorrectedCodewords)
+
+	all := image.Rect(0, 0, qrc.mat.Width(), qrc.mat.Height())
+	assert.Less(t, matchRate(plain, target, all), 0.6)
+	assert.Greater(t, matchRate(qrc, target, all), 0.8)
+
+	// nil target is ignored.
+	same, err := NewWith(qartText, append(opts, WithQArt(nil, nil))...)
+	require.NoError(t, err)
+	assert.Equal(t, plain.mat, same.mat)
+}
+
+func Test_WithQArt_MaskPattern(t *testing.T) {
+	qrc, err := NewWith(qartText, WithVersion(8), WithErrorCorrectionLevel(ErrorCorrectionMedium),
+		WithQArt(diskImage(100), nil), WithMaskPattern(5))
+	require.NoError(t, err)
+	assert.Equal(t, 5, qrc.maskPattern)
+
+	got, err := Decode(*qrc.mat)
+	require.NoError(t, err)
+	assert.Equal(t, qartText, got.Text)
+}
+
+func Test_WithQArt_Priority(t *testing.T) {
+	// the target is all dark, and only the left half is prior.
+	target := image.NewGray(image.Rect(0, 0, 1, 1))
+	priority := image.NewGray(image.Rect(0, 0, 2, 1))
+	priority.SetGray(0, 0, color.Gray{Y: 0xff})
+
+	qrc, err := NewWith(qartText, WithVersion(6), WithErrorCorrectionLevel(ErrorCorrectionLow),
+		WithQArt(diskImage(10), nil), WithQArt(target, priority))
+	require.NoError(t, err)
+
+	w := qrc.mat.Width()
+	left, right := matchRate(qrc, target, image.Rect(0, 0, w/2, w)), matchRate(qrc, target, image.Rect(w/2+1, 0, w, w))
+	assert.Greater(t, left, right)
+	assert.Greater(t, left, 0.8)
+}
+
+func Test_WithQArt_Unavailable(t *testing.T) {
+	_, err := NewMicro("123", WithQArt(diskImage(10), nil))
+	assert.Error(t, err)
+
+	_, err = NewRMQR("123", WithQArt(diskImage(10), nil))
+	assert.Error(t, err)
+
+	// mask pattern is chosen by target image rather than MaskSelector.
+	_, err = NewWith(qartText, WithQArt(diskImage(10), nil), WithMaskSelector(DefaultMaskSelector))
+	assert.Error(t, err)
+}
@@ -3,7 +3,6 @@ package qrcode
 import (
 	"fmt"
 	"log"
-	"math"
 	"sync"
 
 	"github.com/yeqown/reedsolomon"
@@ -13,7 +12,7 @@ import (
 // New generate a QRCode struct to create
 func New[T ~string | ~[]byte](text T) (*QRCode, error) {
 	dst := DefaultEncodingOption()
-	return build(toBytes(text), dst)
+	return build(toBytes(text), nil, dst)
 }
 
 // NewWith generate a QRCode struct with
@@ -24,7 +23,28 @@ func NewWith[T ~string | ~[]byte](text T, opts ...EncodeOption) (*QRCode, error)
 		opt.apply(dst)
 	}
 
-	return build(toBytes(text), dst)
+	return build(toBytes(text), nil, dst)
+}
+
+// NewWithSegments generate a QRCode struct from segments specified by caller,
+// each segment is encoded in its own mode without auto-detection, such as
+// a numeric segment followed by a byte segment. Version is chosen by counting
+// the bits of all segments unless WithVersion is specified.
+func NewWithSegments(segs []Segment, opts ...EncodeOption) (*QRCode, error) {
+	dst := DefaultEncodingOption()
+	for _, opt := range opts {
+		opt.apply(dst)
+	}
+
+	raw := make([]byte, 0, 64)
+	for i, seg := range segs {
+		if err := validateSegment(seg); err != nil {
+			return nil, fmt.Errorf("segment[%d]: %w", i, err)
+		}
+		raw = append(raw, seg.Data...)
+	}
+
+	return build(raw, append(make([]Segment, 0, len(segs)), segs...), dst)
 }
 
 func toBytes[T ~string | ~[]byte](v T) []byte {
@@ -57,19 +77,33 @@ func validateEncodingMode(mode encMode, text string) error {
 		return nil
 	}
 
-	for _, r := range text {
+	for i, r := range text {
 		if !analyzeFn(r) {
-			return fmt.Errorf("character '%c' (U+%04X) cannot be encoded in %s mode",
-				r, r, getEncModeName(mode))
+			return &InvalidCharacterError{Rune: r, Offset: i, Mode: mode}
 		}
 	}
 
 	return nil
 }
 
-func build(raw []byte, option *encodingOption) (*QRCode, error) {
+// validateSegment checks if the segment's mode is supported and
+// its data could be encoded in the mode.
+func validateSegment(seg Segment) error {
+	switch seg.Mode {
+	case EncModeNumeric, EncModeAlphanumeric, EncModeByte, EncModeKanji:
+	default:
+		return fmt.Errorf("%w: %s", errMissMatchedEncodeType, getEncModeName(seg.Mode))
+	}
+
+	return validateEncodingMode(seg.Mode, seg.Data)
+}
+
+// build generate a QRCode from raw data, segs could be nil which means
+// segments would be built from raw according to option.
+func build(raw []byte, segs []Segment, option *encodingOption) (*QRCode, error) {
 	qrc := &QRCode{
 		sourceText:     string(raw),
+		segments:       segs,
 		dataBSet:       nil,
 		mat:            nil,
 		ecBSet:         nil,
@@ -84,6 +118,12 @@ func build(raw []byte, option *encodingOption) (*QRCode, error) {
 
 	qrc.masking()
 
+	if option.Verify {
+		if err := qrc.verify(); err != nil {
+			return nil, err
+		}
+	}
+
 	return qrc, nil
 }
 
@@ -92,6 +132,7 @@ func build(raw []byte, option *encodingOption) (*QRCode, error) {
 type QRCode struct {
 	sourceText string // sourceText input text
 
+	segments []Segment      // segments of sourceText to encode
 	dataBSet *binary.Binary // final data bit stream of encode data
 	mat      *Matrix        // matrix grid to store final bitmap
 	ecBSet   *binary.Binary // final error correction bitset
@@ -99,6 +140,11 @@ type QRCode struct {
 	encodingOption *encodingOption
 	encoder        *encoder // encoder ptr to call its methods ~
 	v              version  // indicate the QR version to encode.
+
+	micro *microVersion // indicate the Micro QR version to encode, nil means regular QR Code.
+	rmqr  *rmqrVersion  // indicate the rMQR version to encode, nil means regular QR Code.
+
+	maskPattern int // the mask pattern reference applied to matrix.
 }
 
 func (q *QRCode) Save(w Writer) error {
@@ -115,6 +161,12 @@ func (q *QRCode) Save(w Writer) error {
 	return w.Write(*q.mat)
 }
 
+// Matrix returns a copy of the final matrix, such as to decode or damage it
+// without a Writer.
+func (q *QRCode) Matrix() Matrix {
+	return *q.mat.Copy()
+}
+
 func (q *QRCode) Dimension() int {
 	if q.mat == nil {
 		return 0
@@ -125,23 +177,10 @@ func (q *QRCode) Dimension() int {
 
 // init fill QRCode instance from settings and sourceText.
 func (q *QRCode) init() (err error) {
-	// choose encode mode (num, alpha num, byte, Japanese)
-	if q.encodingOption.EncMode == EncModeAuto {
-		q.encodingOption.EncMode, err = analyzeEncodeModeFromRaw(q.sourceText)
-		if err != nil {
-			return fmt.Errorf("init: analyze encode mode failed: %v", err)
-		}
-	} else {
-		// Validate that the specified encoding mode is compatible with the input
-		if err = validateEncodingMode(q.encodingOption.EncMode, q.sourceText); err != nil {
-			return err
-		}
+	if err = q.prepare(); err != nil {
+		return err
 	}
 
-	// choose version
-	if _, err = q.calcVersion(); err != nil {
-		return fmt.Errorf("init: calc version failed: %v", err)
-	}
 	q.mat = newMatrix(q.v.Dimension(), q.v.Dimension())
 	_ = q.applyEncoder()
 
@@ -155,6 +194,11 @@ func (q *QRCode) init() (err error) {
 		return err
 	}
 
+	// steer padding codewords to approximate the image of WithQArt.
+	if q.encodingOption.QArt != nil {
+		dataBlocks = q.steer(dataBlocks)
+	}
+
 	// generate er bitsets, and also be split into blocks
 	if ecBlocks, err = q.errorCorrectionEncoding(dataBlocks); err != nil {
 		return err
@@ -172,11 +216,157 @@ func (q *QRCode) init() (err error) {
 	return nil
 }
 
-// calcVersion
-func (q *QRCode) calcVersion() (ver *version, err error) {
+// prepare splits sourceText into segments and chooses version, it's the cheap
+// part of init which doesn't allocate matrix or encode any data.
+func (q *QRCode) prepare() (err error) {
+	if p := q.encodingOption.MaskPattern; p != nil && (*p < 0 || *p > 7) {
+		return fmt.Errorf("init: invalid mask pattern: %d", *p)
+	}
+	if q.encodingOption.QArt != nil && q.encodingOption.MaskSelector != nil {
+		return fmt.Errorf("init: QArt chooses mask pattern by target image, MaskSelector is not available")
+	}
+
+	// segmentsFn returns segments to encode in specified version.
+	var segmentsFn func(ver int) []Segment
+
+	switch {
+	case q.segments != nil:
+		// segments are specified by NewWithSegments, encode them as they are.
+		segs := q.segments
+		segmentsFn = func(int) []Segment { return segs }
+	case q.encodingOption.EncMode == EncModeAuto && q.encodingOption.OptimizeSegments:
+		// the cheapest split depends on version, since the length of
+		// character count indicator changes with version.
+		fnc1 := q.encodingOption.FNC1 != nil
+		segmentsFn = func(ver int) []Segment { return optimizeSegments(q.sourceText, ver, fnc1) }
+	default:
+		seg, err2 := q.singleSegment()
+		if err2 != nil {
+			return err2
+		}
+		segs := []Segment{seg}
+		segmentsFn = func(int) []Segment { return segs }
+	}
+
+	if fnc1 := q.encodingOption.FNC1; fnc1 != nil {
+		if segmentsFn, err = fnc1SegmentsFn(fnc1, segmentsFn); err != nil {
+			return fmt.Errorf("init: %w", err)
+		}
+	}
+	if eci := q.encodingOption.ECI; eci != nil {
+		if segmentsFn, err = eciSegmentsFn(eci, q.sourceText, segmentsFn); err != nil {
+			return fmt.Errorf("init: %w", err)
+		}
+	}
+
+	// choose version
+	if _, err = q.calcVersion(segmentsFn); err != nil {
+		return fmt.Errorf("init: calc version failed: %w", err)
+	}
+	// split segments for the final version, since MinimumVersion may change it.
+	q.segments = segmentsFn(q.v.Ver)
+	q.encodingOption.EncMode = segmentsMode(q.segments)
+
+	// data may overflow the version which is specified by WithVersion.
+	if need, capBits := q.dataBits(), q.v.NumTotalCodewords()*8; need < 0 || need > capBits {
+		return fmt.Errorf("init: %w", &DataTooLongError{Needed: need, Max: capBits, Version: q.v.Ver, ECLevel: q.v.ECLevel})
+	}
+
+	return nil
+}
+
+// singleSegment returns the whole sourceText as one segment in the encode mode
+// specified by option, or analyzed from sourceText if it's EncModeAuto.
+func (q *QRCode) singleSegment() (seg Segment, err error) {
+	// choose encode mode (num, alpha num, byte, Japanese)
+	if q.encodingOption.EncMode == EncModeAuto {
+		q.encodingOption.EncMode, err = analyzeEncodeModeFromRaw(q.sourceText)
+		if err != nil {
+			return seg, fmt.Errorf("init: analyze encode mode failed: %w", err)
+		}
+	} else {
+		// Validate that the specified encoding mode is compatible with the input
+		if err = validateEncodingMode(q.encodingOption.EncMode, q.sourceText); err != nil {
+			return seg, err
+		}
+	}
+
+	return Segment{Mode: q.encodingOption.EncMode, Data: q.sourceText}, nil
+}
+
+// dataBits returns the number of bits used by header and segments before
+// terminator and padding, -1 means segments could not be encoded in current version.
+func (q *QRCode) dataBits() int {
+	n := segmentsBitLen(q.segments, q.v.Ver)
+	if n < 0 {
+		return -1
+	}
+
+	return q.header().Len() + n
+}
+
+// eciSegmentsFn validates eci, and wraps segmentsFn to transcode byte segments
+// into the character set of eci if it's required.
+func eciSegmentsFn(eci *eciOption, text string,
+	segmentsFn func(ver int) []Segment) (func(ver int) []Segment, error) {
+	if err := validateECI(eci.assignment, eci.transcode); err != nil {
+		return nil, err
+	}
+	if !eci.transcode {
+		return segmentsFn, nil
+	}
+
+	// transcode whole text in advance, so that transcoding any part of it
+	// (segments are split at rune boundaries) would never fail.
+	if _, err := transcodeECI(text, eci.assignment); err != nil {
+		return nil, err
+	}
+
+	return func(ver int) []Segment {
+		segs, _ := transcodeSegments(segmentsFn(ver), eci.assignment)
+		return segs
+	}, nil
+}
+
+// fnc1SegmentsFn validates fnc1, and wraps segmentsFn to escape alphanumeric
+// segments, since '%' stands for FNC1 in alphanumeric mode.
+func fnc1SegmentsFn(fnc1 *fnc1Option,
+	segmentsFn func(ver int) []Segment) (func(ver int) []Segment, error) {
+	if fnc1.secondPosition {
+		if _, err := parseAppIndicator(fnc1.appIndicator); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ver int) []Segment {
+		return escapeFNC1Segments(segmentsFn(ver))
+	}, nil
+}
+
+// header returns the bits written before all segments, such as Structured Append
+// header, ECI designator and FNC1 mode indicator.
+func (q *QRCode) header() *binary.Binary {
+	b := binary.New()
+	if sa := q.encodingOption.structuredAppend; sa != nil {
+		b.Append(sa.header())
+	}
+	if eci := q.encodingOption.ECI; eci != nil {
+		b.Append(eciHeader(eci.assignment))
+	}
+	if fnc1 := q.encodingOption.FNC1; fnc1 != nil {
+		b.Append(fnc1.header())
+	}
+
+	return b
+}
+
+// calcVersion chooses version by counting the bits of header and all segments returned by segmentsFn.
+func (q *QRCode) calcVersion(segmentsFn func(ver int) []Segment) (ver *version, err error) {
 	var needAnalyze = true
 
 	opt := q.encodingOption
+	// analyzedVer is the version data fits in, before minimum version applied.
+	analyzedVer := opt.Version
 	if opt.Version >= 1 && opt.Version <= 40 &&
 		opt.EcLevel >= ErrorCorrectionLow && opt.EcLevel <= ErrorCorrectionHighest {
 		// only version and EC level are specified, can skip analyzeVersionAuto
@@ -185,13 +375,19 @@ func (q *QRCode) calcVersion() (ver *version, err error) {
 
 	// automatically parse version
 	if needAnalyze {
-		// analyzeVersion the input data to choose to adapt version
-		analyzed, err2 := analyzeVersion(q.sourceText, opt.EcLevel, opt.EncMode)
+		// the highest EC level which fits in maximum version is preferred while boosting.
+		if opt.BoostErrorCorrection && opt.MaximumVersion > 0 {
+			q.boostErrorCorrectionUnder(opt.MaximumVersion, segmentsFn)
+		}
+
+		// analyze the input data to choose to adapt version
+		analyzed, err2 := analyzeVersionBySegments(opt.EcLevel, q.header().Len(), segmentsFn)
 		if err2 != nil {
-			err = fmt.Errorf("calcVersion: analyzeVersionAuto failed: %v", err2)
+			err = fmt.Errorf("calcVersion: analyzeVersionAuto failed: %w", err2)
 			return nil, err
 		}
 		opt.Version = analyzed.Ver
+		analyzedVer = analyzed.Ver
 
 		// Apply minimum version constraint if set
 		if opt.MinimumVersion > 0 && opt.Version < opt.MinimumVersion {
@@ -199,14 +395,73 @@ func (q *QRCode) calcVersion() (ver *version, err error) {
 		}
 	}
 
+	// Apply maximum version constraint if set
+	if maxVer := opt.MaximumVersion; maxVer > 0 && opt.Version > maxVer {
+		// bits are counted in the analyzed version, since character count indicator
+		// of segments may overflow in maximum version.
+		err = fmt.Errorf("calcVersion: maximum version exceeded: %w", &DataTooLongError{
+			Needed:  q.neededBits(analyzedVer, segmentsFn),
+			Max:     loadVersion(maxVer, opt.EcLevel).NumTotalCodewords() * 8,
+			Version: maxVer,
+			ECLevel: opt.EcLevel,
+		})
+		return nil, err
+	}
+
 	q.v = loadVersion(opt.Version, opt.EcLevel)
 
+	if opt.BoostErrorCorrection {
+		q.boostErrorCorrection(segmentsFn)
+	}
+
 	return
 }
 
+// neededBits returns the number of bits used by header and segments in version ver,
+// -1 means segments could not be encoded in version ver.
+func (q *QRCode) neededBits(ver int, segmentsFn func(ver int) []Segment) int {
+	need := segmentsBitLen(segmentsFn(ver), ver)
+	if need < 0 {
+		return -1
+	}
+
+	return need + q.header().Len()
+}
+
+// boostErrorCorrection raises EC level as high as the chosen version could still
+// contain header and all segments, so that the size of symbol doesn't change.
+func (q *QRCode) boostErrorCorrection(segmentsFn func(ver int) []Segment) {
+	need := q.neededBits(q.v.Ver, segmentsFn)
+	if need < 0 {
+		return
+	}
+
+	for ec := ErrorCorrectionHighest; ec > q.encodingOption.EcLevel; ec-- {
+		if v := loadVersion(q.v.Ver, ec); v.NumTotalCodewords()*8 >= need {
+			q.v = v
+			q.encodingOption.EcLevel = ec
+			return
+		}
+	}
+}
+
+// boostErrorCorrectionUnder raises EC level as high as data could still be contained
+// by a version no larger than maxVer, the version would be chosen later by the raised EC level.
+func (q *QRCode) boostErrorCorrectionUnder(maxVer int, segmentsFn func(ver int) []Segment) {
+	opt := q.encodingOption
+	for ec := ErrorCorrectionHighest; ec > opt.EcLevel; ec-- {
+		analyzed, err := analyzeVersionBySegments(ec, q.header().Len(), segmentsFn)
+		if err == nil && max(analyzed.Ver, opt.MinimumVersion) <= maxVer {
+			opt.EcLevel = ec
+			return
+		}
+	}
+}
+
 // applyEncoder
 func (q *QRCode) applyEncoder() error {
 	q.encoder = newEncoder(q.encodingOption.EncMode, q.encodingOption.EcLevel, q.v)
+	q.encoder.header = q.header()
 
 	return nil
 }
@@ -217,9 +472,9 @@ func (q *QRCode) dataEncoding() (blocks []dataBlock, err error) {
 	var (
 		bset *binary.Binary
 	)
-	bset, err = q.encoder.Encode(q.sourceText)
+	bset, err = q.encoder.EncodeSegments(q.segments)
 	if err != nil {
-		err = fmt.Errorf("could not encode data: %v", err)
+		err = fmt.Errorf("could not encode data: %w", err)
 		return
 	}
 
@@ -475,11 +730,11 @@ func addSplitter(m *Matrix, x, y, dimension int) {
 
 // add matrix align module
 func addAlignment(m *Matrix, centerX, centerY int) {
-	_ = m.set(centerX, centerY, QRValue_DATA_V1)
+	_ = m.set(centerX, centerY, QRValue_ALIGNMENT_V1)
 	// black
 	x, y := centerX-2, centerY-2
 	for i := 0; i < 16; i++ {
-		_ = m.set(x, y, QRValue_DATA_V1)
+		_ = m.set(x, y, QRValue_ALIGNMENT_V1)
 		if i < 4 {
 			x = x + 1
 		} else if i < 8 {
@@ -493,7 +748,7 @@ func addAlignment(m *Matrix, centerX, centerY int) {
 	// white
 	x, y = centerX-1, centerY-1
 	for i := 0; i < 8; i++ {
-		_ = m.set(x, y, QRValue_DATA_V0)
+		_ = m.set(x, y, QRValue_ALIGNMENT_V0)
 		if i < 2 {
 			x = x + 1
 		} else if i < 4 {
@@ -637,22 +892,54 @@ func (q *QRCode) fillDataBinary(m *Matrix, dimension int) {
 	debugLogf("fillDone and x: %d, y: %d, pos: %d, total: %d", x, y, pos, l)
 }
 
+// fillDataColumns fills bits into m in 2 modules wide columns from the bottom of
+// column right, moving upwards and downwards alternately, and skips all function
+// modules. It's used by Micro QR Code and rMQR Code, which have no vertical
+// timing pattern to skip like regular QR Code, modules left are filled by 0.
+func fillDataColumns(m *Matrix, bits *binary.Binary, right int) {
+	pos, upward := 0, true
+	for ; right >= 1; right -= 2 {
+		for i := 0; i < m.Height(); i++ {
+			y := i
+			if upward {
+				y = m.Height() - 1 - i
+			}
+
+			for x := right; x >= right-1; x-- {
+				if v, _ := m.at(x, y); v.qrtype() != QRType_INIT {
+					continue
+				}
+
+				value := QRValue_DATA_V0
+				if pos < bits.Len() && bits.At(pos) {
+					value = QRValue_DATA_V1
+				}
+				_ = m.set(x, y, value)
+				pos++
+			}
+		}
+		upward = !upward
+	}
+}
+
 // draw from bitset to matrix.Matrix, calculate all mask modula score,
 // then decide which mask to use according to the mask's score (the lowest one).
+// Only the pattern specified by WithMaskPattern is applied if it's set, and
+// MaskSelector specified by WithMaskSelector replaces the default evaluation.
 func (q *QRCode) masking() {
-	type maskScore struct {
-		Score int
-		Idx   int
-	}
-
 	var (
-		masks       = make([]*mask, 8)
-		mats        = make([]*Matrix, 8)
-		lowScore    = math.MaxInt32
-		markMatsIdx int
-		scoreChan   = make(chan maskScore, 8)
-		wg          sync.WaitGroup
+		patterns = []int{0, 1, 2, 3, 4, 5, 6, 7}
+		mats     = make([]*Matrix, 8)
+		scores   = make([]int, 8)
+		selector = q.encodingOption.MaskSelector
+		wg       sync.WaitGroup
 	)
+	if p := q.encodingOption.MaskPattern; p != nil {
+		patterns = []int{*p}
+	}
+	if selector == nil {
+		selector = DefaultMaskSelector
+	}
 
 	dimension := q.v.Dimension()
 
@@ -660,21 +947,19 @@ func (q *QRCode) masking() {
 	cpy := q.mat.Copy()
 	q.fillDataBinary(cpy, dimension)
 
-	// init mask and mats
-	for i := 0; i < 8; i++ {
-		masks[i] = newMask(q.mat, maskPatternModulo(i))
-		mats[i] = cpy.Copy()
-	}
-
-	// generate 8 matrix with mask
-	for i := 0; i < 8; i++ {
+	// generate matrix with each mask
+	for _, i := range patterns {
 		wg.Add(1)
 		go func(i int) {
+			defer wg.Done()
+
+			mask := newMask(q.mat, maskPatternModulo(i))
+			mats[i] = cpy.Copy()
 			_ = debugDraw(fmt.Sprintf("draft/mats_%d.jpeg", i), *mats[i])
-			_ = debugDraw(fmt.Sprintf("draft/mask_%d.jpeg", i), *masks[i].mat)
+			_ = debugDraw(fmt.Sprintf("draft/mask_%d.jpeg", i), *mask.mat)
 
 			// xor with mask
-			q.xorMask(mats[i], masks[i])
+			q.xorMask(mats[i], mask)
 
 			_ = debugDraw(fmt.Sprintf("draft/mats_mask_%d.jpeg", i), *mats[i])
 
@@ -685,35 +970,25 @@ func (q *QRCode) masking() {
 				q.fillVersionInfo(mats[i], dimension)
 			}
 
-			// calculate score and decide the lowest score and Draw
-			score := evaluation(mats[i])
-			debugLogf("cur idx: %d, score: %d, current lowest: mats[%d]:%d",
-				i,
-				score,
-				markMatsIdx,
-				lowScore)
-			scoreChan <- maskScore{
-				Score: score,
-				Idx:   i,
-			}
+			// calculate score of each mask, the lowest one would be chosen.
+			scores[i] = selector.Penalty(*mats[i], i)
+			debugLogf("cur idx: %d, score: %d", i, scores[i])
 
 			_ = debugDraw(fmt.Sprintf("draft/qrcode_mask_%d.jpeg", i), *mats[i])
-
-			wg.Done()
 		}(i)
 	}
 
 	wg.Wait()
-	close(scoreChan)
 
-	for c := range scoreChan {
-		if c.Score < lowScore {
-			lowScore = c.Score
-			markMatsIdx = c.Idx
+	// the lower pattern wins if scores are equal, so that the result is stable.
+	q.maskPattern = patterns[0]
+	for _, i := range patterns[1:] {
+		if scores[i] < scores[q.maskPattern] {
+			q.maskPattern = i
 		}
 	}
 
-	q.mat = mats[markMatsIdx]
+	q.mat = mats[q.maskPattern]
 }
 
 // all mask patter and check the maskScore choose the lowest mask result
@@ -308,3 +308,166 @@ func Test_NewWith_Kanji_Version10(t *testing.T) {
 
 	t.Logf("Kanji QR code with version 10: matrix dimension=%d", qrc.mat.Width())
 }
+
+// Test_NewWithSegments tests that segments are encoded as they are specified.
+func Test_NewWithSegments(t *testing.T) {
+	segs := []Segment{
+		{Mode: EncModeNumeric, Data: "0123456789"},
+		{Mode: EncModeByte, Data: "hello"},
+	}
+
+	qrc, err := NewWithSegments(segs, WithErrorCorrectionLevel(ErrorCorrectionLow))
+	require.NoError(t, err)
+	assert.Equal(t, segs, qrc.segments)
+	assert.Equal(t, "0123456789hello", qrc.sourceText)
+	assert.Equal(t, encMode(EncModeNone), qrc.encoder.mode)
+	// 4+10+34 bits numeric and 4+8+40 bits byte fit in version 1-L (152 bits).
+	assert.Equal(t, 1, qrc.v.Ver)
+
+	// modifying segments after NewWithSegments should not affect the QRCode.
+	segs[0].Data = "9"
+	assert.Equal(t, "0123456789", qrc.segments[0].Data)
+}
+
+// Test_NewWithSegments_Invalid tests that each segment is validated.
+func Test_NewWithSegments_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		segs        []Segment
+		expectedErr string
+	}{
+		{
+			name:        "letters in numeric segment",
+			segs:        []Segment{{Mode: EncModeNumeric, Data: "12a"}},
+			expectedErr: "segment[0]: character 'a' (U+0061) cannot be encoded in numeric mode",
+		},
+		{
+			name: "lowercase in alphanumeric segment",
+			segs: []Segment{
+				{Mode: EncModeByte, Data: "abc"},
+				{Mode: EncModeAlphanumeric, Data: "abc"},
+			},
+			expectedErr: "segment[1]: character 'a' (U+0061) cannot be encoded in alphanumeric mode",
+		},
+		{
+			name:        "unsupported mode",
+			segs:        []Segment{{Mode: EncModeAuto, Data: "123"}},
+			expectedErr: "segment[0]: could not match the encode type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWithSegments(tt.segs)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+// Test_NewWithSegments_TooLong tests that version selection counts bits across all segments.
+func Test_NewWithSegments_TooLong(t *testing.T) {
+	// each segment fits in version 40-H alone, but not together.
+	segs := []Segment{
+		{Mode: EncModeByte, Data: strings.Repeat("a", 1000)},
+		{Mode: EncModeByte, Data: strings.Repeat("b", 1000)},
+	}
+
+	_, err := NewWithSegments(segs, WithErrorCorrectionLevel(ErrorCorrectionHighest))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), errAnalyzeVersionFailed.Error())
+}
+
+func Test_WithBoostErrorCorrection(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		opts    []EncodeOption
+		version int
+		ec      ecLevel
+	}{
+		{name: "boost to highest", text: "HELLO", opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionLow)}, version: 1, ec: ErrorCorrectionHighest},
+		// 20 alphanumeric characters take 123 bits, 1-M contains 128 bits and 1-Q 104 bits.
+		{name: "boost to medium", text: "HELLO WORLD 12345678", opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionLow)}, version: 1, ec: ErrorCorrectionMedium},
+		{name: "no room", text: "HELLO WORLD 12345678", opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionMedium)}, version: 1, ec: ErrorCorrectionMedium},
+		{name: "specified version", text: "HELLO WORLD 12345678", opts: []EncodeOption{WithVersion(2), WithErrorCorrectionLevel(ErrorCorrectionLow)}, version: 2, ec: ErrorCorrectionHighest},
+		{name: "minimum version", text: strings.Repeat("boost", 10), opts: []EncodeOption{WithMinimumVersion(10), WithErrorCorrectionLevel(ErrorCorrectionLow)}, version: 10, ec: ErrorCorrectionHighest},
+		{name: "with header", text: "0123456789abc", opts: []EncodeOption{WithOptimizedSegments(), WithUTF8ECI(), WithErrorCorrectionLevel(ErrorCorrectionLow)}, version: 1, ec: ErrorCorrectionQuart},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, err := NewWith(tt.text, tt.opts...)
+			require.NoError(t, err)
+
+			qrc, err := NewWith(tt.text, append(tt.opts, WithBoostErrorCorrection(), WithVerify())...)
+			require.NoError(t, err)
+			assert.Equal(t, plain.Dimension(), qrc.Dimension())
+
+			info := qrc.Info()
+			assert.Equal(t, tt.version, info.Version)
+			assert.Equal(t, tt.ec, info.ECLevel)
+			assert.LessOrEqual(t, info.DataBits, info.CapacityBits)
+
+			result, err := Decode(*qrc.mat)
+			require.NoError(t, err)
+			assert.Equal(t, tt.ec, result.ECLevel)
+		})
+	}
+}
+
+func Test_WithMaximumVersion(t *testing.T) {
+	qrc, err := NewWith("hello", WithMaximumVersion(10))
+	require.NoError(t, err)
+	assert.Equal(t, 1, qrc.Info().Version)
+
+	// 300 bytes need version 11-L.
+	text := strings.Repeat("a", 300)
+	qrc, err = NewWith(text, WithMaximumVersion(11), WithErrorCorrectionLevel(ErrorCorrectionLow))
+	require.NoError(t, err)
+	assert.Equal(t, 11, qrc.Info().Version)
+
+	_, err = NewWith(text, WithMaximumVersion(10), WithErrorCorrectionLevel(ErrorCorrectionLow))
+	var tooLong *DataTooLongError
+	require.ErrorAs(t, err, &tooLong)
+	assert.Equal(t, DataTooLongError{Needed: 4 + 16 + 300*8, Max: 274 * 8, Version: 10, ECLevel: ErrorCorrectionLow}, *tooLong)
+
+	// the estimation fails as well.
+	_, err = Estimate(text, WithMaximumVersion(10), WithErrorCorrectionLevel(ErrorCorrectionLow))
+	assert.ErrorAs(t, err, &tooLong)
+
+	// conflicts with minimum version or specified version.
+	_, err = NewWith("hello", WithMinimumVersion(12), WithMaximumVersion(10))
+	assert.ErrorAs(t, err, &tooLong)
+	_, err = NewWith("hello", WithVersion(12), WithMaximumVersion(10))
+	assert.ErrorAs(t, err, &tooLong)
+}
+
+func Test_WithMaximumVersion_BoostErrorCorrection(t *testing.T) {
+	text := strings.Repeat("a", 100)
+
+	// 100 bytes need version 5-L, and 5-M could not contain them.
+	qrc, err := NewWith(text, WithErrorCorrectionLevel(ErrorCorrectionLow), WithBoostErrorCorrection())
+	require.NoError(t, err)
+	assert.Equal(t, 5, qrc.Info().Version)
+	assert.Equal(t, ErrorCorrectionLow, qrc.Info().ECLevel)
+
+	tests := []struct {
+		maxVersion int
+		version    int
+		ec         ecLevel
+	}{
+		{maxVersion: 5, version: 5, ec: ErrorCorrectionLow},
+		{maxVersion: 6, version: 6, ec: ErrorCorrectionMedium},
+		{maxVersion: 8, version: 8, ec: ErrorCorrectionQuart},
+		{maxVersion: 10, version: 10, ec: ErrorCorrectionHighest},
+		{maxVersion: 20, version: 10, ec: ErrorCorrectionHighest},
+	}
+	for _, tt := range tests {
+		qrc, err = NewWith(text, WithErrorCorrectionLevel(ErrorCorrectionLow), WithBoostErrorCorrection(),
+			WithMaximumVersion(tt.maxVersion), WithVerify())
+		require.NoError(t, err)
+		assert.Equal(t, tt.version, qrc.Info().Version, "max version %d", tt.maxVersion)
+		assert.Equal(t, tt.ec, qrc.Info().ECLevel, "max version %d", tt.maxVersion)
+	}
+}
@@ -0,0 +1,465 @@
+package qrcode
+
+import (
+	"fmt"
+
+	"github.com/yeqown/reedsolomon/binary"
+)
+
+const (
+	// rmqrFormatInfoBitsNum is the length of rMQR format info, 6 data bits and 12 BCH bits.
+	rmqrFormatInfoBitsNum = 18
+
+	// rmqrFormatGenerator is the generator polynomial of BCH (18, 6) code,
+	// x^12 + x^11 + x^10 + x^9 + x^8 + x^5 + x^2 + 1, same as QR Code version info.
+	rmqrFormatGenerator = 0x1F25
+
+	// rmqrFormatMaskFinder and rmqrFormatMaskSubFinder are XORed with format info
+	// placed beside finder pattern and sub-finder pattern respectively.
+	rmqrFormatMaskFinder    = 0x1FAB2
+	rmqrFormatMaskSubFinder = 0x20A7B
+)
+
+// rmqrVersion contains information about each rMQR Code (Rectangular Micro QR Code)
+// symbol, which is identified by its size R{Height}x{Width} and ECLevel.
+//
+// reference:
+// - ISO/IEC 23941:2022 Table 3, Table 6 and Table 8
+type rmqrVersion struct {
+	// Indicator is the size indicator (0-31) of R{Height}x{Width} in format info.
+	Indicator int
+
+	// Height and Width of symbol in modules.
+	Height int
+	Width  int
+
+	// ECLevel error correction level, only M and H are available in rMQR Code.
+	ECLevel ecLevel
+
+	// RemainderBits remainder bits need to append finally.
+	RemainderBits int
+
+	// CharCountBits the length of character count indicator of numeric,
+	// alphanumeric, byte and kanji mode.
+	CharCountBits [4]int
+
+	// Groups of data and error correction blocks, same as QR Code.
+	Groups []group
+}
+
+// rmqrVersions contains information about each rMQR Code size.
+// NOTICE: item MUST keep sorted according to Indicator and ECLevel (ASC).
+var rmqrVersions = []rmqrVersion{
+	{Indicator: 0, Height: 7, Width: 43, ECLevel: ErrorCorrectionMedium, RemainderBits: 0, CharCountBits: [4]int{4, 3, 3, 2}, Groups: []group{{1, 6, 7}}},
+	{Indicator: 0, Height: 7, Width: 43, ECLevel: ErrorCorrectionHighest, RemainderBits: 0, CharCountBits: [4]int{4, 3, 3, 2}, Groups: []group{{1, 3, 10}}},
+	{Indicator: 1, Height: 7, Width: 59, ECLevel: ErrorCorrectionMedium, RemainderBits: 3, CharCountBits: [4]int{5, 5, 4, 3}, Groups: []group{{1, 12, 9}}},
+	{Indicator: 1, Height: 7, Width: 59, ECLevel: ErrorCorrectionHighest, RemainderBits: 3, CharCountBits: [4]int{5, 5, 4, 3}, Groups: []group{{1, 7, 14}}},
+	{Indicator: 2, Height: 7, Width: 77, ECLevel: ErrorCorrectionMedium, RemainderBits: 5, CharCountBits: [4]int{6, 5, 5, 4}, Groups: []group{{1, 20, 12}}},
+	{Indicator: 2, Height: 7, Width: 77, ECLevel: ErrorCorrectionHighest, RemainderBits: 5, CharCountBits: [4]int{6, 5, 5, 4}, Groups: []group{{1, 10, 22}}},
+	{Indicator: 3, Height: 7, Width: 99, ECLevel: ErrorCorrectionMedium, RemainderBits: 6, CharCountBits: [4]int{7, 6, 5, 5}, Groups: []group{{1, 28, 16}}},
+	{Indicator: 3, Height: 7, Width: 99, ECLevel: ErrorCorrectionHighest, RemainderBits: 6, CharCountBits: [4]int{7, 6, 5, 5}, Groups: []group{{1, 14, 30}}},
+	{Indicator: 4, Height: 7, Width: 139, ECLevel: ErrorCorrectionMedium, RemainderBits: 1, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{1, 44, 24}}},
+	{Indicator: 4, Height: 7, Width: 139, ECLevel: ErrorCorrectionHighest, RemainderBits: 1, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{2, 12, 22}}},
+	{Indicator: 5, Height: 9, Width: 43, ECLevel: ErrorCorrectionMedium, RemainderBits: 2, CharCountBits: [4]int{5, 5, 4, 3}, Groups: []group{{1, 12, 9}}},
+	{Indicator: 5, Height: 9, Width: 43, ECLevel: ErrorCorrectionHighest, RemainderBits: 2, CharCountBits: [4]int{5, 5, 4, 3}, Groups: []group{{1, 7, 14}}},
+	{Indicator: 6, Height: 9, Width: 59, ECLevel: ErrorCorrectionMedium, RemainderBits: 3, CharCountBits: [4]int{6, 5, 5, 4}, Groups: []group{{1, 21, 12}}},
+	{Indicator: 6, Height: 9, Width: 59, ECLevel: ErrorCorrectionHighest, RemainderBits: 3, CharCountBits: [4]int{6, 5, 5, 4}, Groups: []group{{1, 11, 22}}},
+	{Indicator: 7, Height: 9, Width: 77, ECLevel: ErrorCorrectionMedium, RemainderBits: 1, CharCountBits: [4]int{7, 6, 5, 5}, Groups: []group{{1, 31, 18}}},
+	{Indicator: 7, Height: 9, Width: 77, ECLevel: ErrorCorrectionHighest, RemainderBits: 1, CharCountBits: [4]int{7, 6, 5, 5}, Groups: []group{{1, 8, 16}, {1, 9, 16}}},
+	{Indicator: 8, Height: 9, Width: 99, ECLevel: ErrorCorrectionMedium, RemainderBits: 4, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{1, 42, 24}}},
+	{Indicator: 8, Height: 9, Width: 99, ECLevel: ErrorCorrectionHighest, RemainderBits: 4, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{2, 11, 22}}},
+	{Indicator: 9, Height: 9, Width: 139, ECLevel: ErrorCorrectionMedium, RemainderBits: 5, CharCountBits: [4]int{8, 7, 6, 6}, Groups: []group{{1, 31, 18}, {1, 32, 18}}},
+	{Indicator: 9, Height: 9, Width: 139, ECLevel: ErrorCorrectionHighest, RemainderBits: 5, CharCountBits: [4]int{8, 7, 6, 6}, Groups: []group{{3, 11, 22}}},
+	{Indicator: 10, Height: 11, Width: 27, ECLevel: ErrorCorrectionMedium, RemainderBits: 2, CharCountBits: [4]int{4, 4, 3, 2}, Groups: []group{{1, 7, 8}}},
+	{Indicator: 10, Height: 11, Width: 27, ECLevel: ErrorCorrectionHighest, RemainderBits: 2, CharCountBits: [4]int{4, 4, 3, 2}, Groups: []group{{1, 5, 10}}},
+	{Indicator: 11, Height: 11, Width: 43, ECLevel: ErrorCorrectionMedium, RemainderBits: 1, CharCountBits: [4]int{6, 5, 5, 4}, Groups: []group{{1, 19, 12}}},
+	{Indicator: 11, Height: 11, Width: 43, ECLevel: ErrorCorrectionHighest, RemainderBits: 1, CharCountBits: [4]int{6, 5, 5, 4}, Groups: []group{{1, 11, 20}}},
+	{Indicator: 12, Height: 11, Width: 59, ECLevel: ErrorCorrectionMedium, RemainderBits: 0, CharCountBits: [4]int{7, 6, 5, 5}, Groups: []group{{1, 31, 16}}},
+	{Indicator: 12, Height: 11, Width: 59, ECLevel: ErrorCorrectionHighest, RemainderBits: 0, CharCountBits: [4]int{7, 6, 5, 5}, Groups: []group{{1, 7, 16}, {1, 8, 16}}},
+	{Indicator: 13, Height: 11, Width: 77, ECLevel: ErrorCorrectionMedium, RemainderBits: 2, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{1, 43, 24}}},
+	{Indicator: 13, Height: 11, Width: 77, ECLevel: ErrorCorrectionHighest, RemainderBits: 2, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{1, 11, 22}, {1, 12, 22}}},
+	{Indicator: 14, Height: 11, Width: 99, ECLevel: ErrorCorrectionMedium, RemainderBits: 7, CharCountBits: [4]int{8, 7, 6, 6}, Groups: []group{{1, 28, 16}, {1, 29, 16}}},
+	{Indicator: 14, Height: 11, Width: 99, ECLevel: ErrorCorrectionHighest, RemainderBits: 7, CharCountBits: [4]int{8, 7, 6, 6}, Groups: []group{{1, 14, 30}, {1, 15, 30}}},
+	{Indicator: 15, Height: 11, Width: 139, ECLevel: ErrorCorrectionMedium, RemainderBits: 6, CharCountBits: [4]int{8, 7, 7, 6}, Groups: []group{{2, 42, 24}}},
+	{Indicator: 15, Height: 11, Width: 139, ECLevel: ErrorCorrectionHighest, RemainderBits: 6, CharCountBits: [4]int{8, 7, 7, 6}, Groups: []group{{3, 14, 30}}},
+	{Indicator: 16, Height: 13, Width: 27, ECLevel: ErrorCorrectionMedium, RemainderBits: 4, CharCountBits: [4]int{5, 5, 4, 3}, Groups: []group{{1, 12, 9}}},
+	{Indicator: 16, Height: 13, Width: 27, ECLevel: ErrorCorrectionHighest, RemainderBits: 4, CharCountBits: [4]int{5, 5, 4, 3}, Groups: []group{{1, 7, 14}}},
+	{Indicator: 17, Height: 13, Width: 43, ECLevel: ErrorCorrectionMedium, RemainderBits: 1, CharCountBits: [4]int{6, 6, 5, 5}, Groups: []group{{1, 27, 14}}},
+	{Indicator: 17, Height: 13, Width: 43, ECLevel: ErrorCorrectionHighest, RemainderBits: 1, CharCountBits: [4]int{6, 6, 5, 5}, Groups: []group{{1, 13, 28}}},
+	{Indicator: 18, Height: 13, Width: 59, ECLevel: ErrorCorrectionMedium, RemainderBits: 6, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{1, 38, 22}}},
+	{Indicator: 18, Height: 13, Width: 59, ECLevel: ErrorCorrectionHighest, RemainderBits: 6, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{2, 10, 20}}},
+	{Indicator: 19, Height: 13, Width: 77, ECLevel: ErrorCorrectionMedium, RemainderBits: 4, CharCountBits: [4]int{7, 7, 6, 6}, Groups: []group{{1, 26, 16}, {1, 27, 16}}},
+	{Indicator: 19, Height: 13, Width: 77, ECLevel: ErrorCorrectionHighest, RemainderBits: 4, CharCountBits: [4]int{7, 7, 6, 6}, Groups: []group{{1, 14, 28}, {1, 15, 28}}},
+	{Indicator: 20, Height: 13, Width: 99, ECLevel: ErrorCorrectionMedium, RemainderBits: 3, CharCountBits: [4]int{8, 7, 7, 6}, Groups: []group{{1, 36, 20}, {1, 37, 20}}},
+	{Indicator: 20, Height: 13, Width: 99, ECLevel: ErrorCorrectionHighest, RemainderBits: 3, CharCountBits: [4]int{8, 7, 7, 6}, Groups: []group{{1, 11, 26}, {2, 12, 26}}},
+	{Indicator: 21, Height: 13, Width: 139, ECLevel: ErrorCorrectionMedium, RemainderBits: 0, CharCountBits: [4]int{8, 8, 7, 7}, Groups: []group{{2, 35, 20}, {1, 36, 20}}},
+	{Indicator: 21, Height: 13, Width: 139, ECLevel: ErrorCorrectionHighest, RemainderBits: 0, CharCountBits: [4]int{8, 8, 7, 7}, Groups: []group{{2, 13, 28}, {2, 14, 28}}},
+	{Indicator: 22, Height: 15, Width: 43, ECLevel: ErrorCorrectionMedium, RemainderBits: 1, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{1, 33, 18}}},
+	{Indicator: 22, Height: 15, Width: 43, ECLevel: ErrorCorrectionHighest, RemainderBits: 1, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{1, 7, 18}, {1, 8, 18}}},
+	{Indicator: 23, Height: 15, Width: 59, ECLevel: ErrorCorrectionMedium, RemainderBits: 4, CharCountBits: [4]int{7, 7, 6, 5}, Groups: []group{{1, 48, 26}}},
+	{Indicator: 23, Height: 15, Width: 59, ECLevel: ErrorCorrectionHighest, RemainderBits: 4, CharCountBits: [4]int{7, 7, 6, 5}, Groups: []group{{2, 13, 24}}},
+	{Indicator: 24, Height: 15, Width: 77, ECLevel: ErrorCorrectionMedium, RemainderBits: 6, CharCountBits: [4]int{8, 7, 7, 6}, Groups: []group{{1, 33, 18}, {1, 34, 18}}},
+	{Indicator: 24, Height: 15, Width: 77, ECLevel: ErrorCorrectionHighest, RemainderBits: 6, CharCountBits: [4]int{8, 7, 7, 6}, Groups: []group{{2, 10, 24}, {1, 11, 24}}},
+	{Indicator: 25, Height: 15, Width: 99, ECLevel: ErrorCorrectionMedium, RemainderBits: 7, CharCountBits: [4]int{8, 7, 7, 6}, Groups: []group{{2, 44, 24}}},
+	{Indicator: 25, Height: 15, Width: 99, ECLevel: ErrorCorrectionHighest, RemainderBits: 7, CharCountBits: [4]int{8, 7, 7, 6}, Groups: []group{{4, 12, 22}}},
+	{Indicator: 26, Height: 15, Width: 139, ECLevel: ErrorCorrectionMedium, RemainderBits: 2, CharCountBits: [4]int{9, 8, 7, 7}, Groups: []group{{2, 42, 24}, {1, 43, 24}}},
+	{Indicator: 26, Height: 15, Width: 139, ECLevel: ErrorCorrectionHighest, RemainderBits: 2, CharCountBits: [4]int{9, 8, 7, 7}, Groups: []group{{1, 13, 26}, {4, 14, 26}}},
+	{Indicator: 27, Height: 17, Width: 43, ECLevel: ErrorCorrectionMedium, RemainderBits: 1, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{1, 39, 22}}},
+	{Indicator: 27, Height: 17, Width: 43, ECLevel: ErrorCorrectionHighest, RemainderBits: 1, CharCountBits: [4]int{7, 6, 6, 5}, Groups: []group{{1, 10, 20}, {1, 11, 20}}},
+	{Indicator: 28, Height: 17, Width: 59, ECLevel: ErrorCorrectionMedium, RemainderBits: 2, CharCountBits: [4]int{8, 7, 6, 6}, Groups: []group{{2, 28, 16}}},
+	{Indicator: 28, Height: 17, Width: 59, ECLevel: ErrorCorrectionHighest, RemainderBits: 2, CharCountBits: [4]int{8, 7, 6, 6}, Groups: []group{{2, 14, 30}}},
+	{Indicator: 29, Height: 17, Width: 77, ECLevel: ErrorCorrectionMedium, RemainderBits: 0, CharCountBits: [4]int{8, 7, 7, 6}, Groups: []group{{2, 39, 22}}},
+	{Indicator: 29, Height: 17, Width: 77, ECLevel: ErrorCorrectionHighest, RemainderBits: 0, CharCountBits: [4]int{8, 7, 7, 6}, Groups: []group{{1, 12, 28}, {2, 13, 28}}},
+	{Indicator: 30, Height: 17, Width: 99, ECLevel: ErrorCorrectionMedium, RemainderBits: 3, CharCountBits: [4]int{8, 8, 7, 6}, Groups: []group{{2, 33, 20}, {1, 34, 20}}},
+	{Indicator: 30, Height: 17, Width: 99, ECLevel: ErrorCorrectionHighest, RemainderBits: 3, CharCountBits: [4]int{8, 8, 7, 6}, Groups: []group{{4, 14, 26}}},
+	{Indicator: 31, Height: 17, Width: 139, ECLevel: ErrorCorrectionMedium, RemainderBits: 4, CharCountBits: [4]int{9, 8, 8, 7}, Groups: []group{{4, 38, 20}}},
+	{Indicator: 31, Height: 17, Width: 139, ECLevel: ErrorCorrectionHighest, RemainderBits: 4, CharCountBits: [4]int{9, 8, 8, 7}, Groups: []group{{2, 12, 26}, {4, 13, 26}}},
+}
+
+// rmqrAlignmentColumns contains the center columns of alignment patterns of each width.
+var rmqrAlignmentColumns = map[int][]int{
+	27:  nil,
+	43:  {21},
+	59:  {19, 39},
+	77:  {25, 51},
+	99:  {23, 49, 75},
+	139: {27, 55, 83, 111},
+}
+
+// qrVersion converts v into version, so that data encoding, error correction
+// and arranging blocks of QR Code could be reused.
+func (v rmqrVersion) qrVersion() version {
+	return version{
+		ECLevel:       v.ECLevel,
+		RemainderBits: v.RemainderBits,
+		Groups:        v.Groups,
+	}
+}
+
+// modeIndicator returns the 3 bits mode indicator value of mode, numeric 1,
+// alphanumeric 2, byte 3 and kanji 4.
+func (v rmqrVersion) modeIndicator(mode encMode) uint32 {
+	switch mode {
+	case EncModeAlphanumeric:
+		return 2
+	case EncModeByte:
+		return 3
+	case EncModeKanji:
+		return 4
+	}
+
+	return 1
+}
+
+// charCountBits returns the length of character count indicator of mode.
+func (v rmqrVersion) charCountBits(mode encMode) int {
+	switch mode {
+	case EncModeAlphanumeric:
+		return v.CharCountBits[1]
+	case EncModeByte:
+		return v.CharCountBits[2]
+	case EncModeKanji:
+		return v.CharCountBits[3]
+	}
+
+	return v.CharCountBits[0]
+}
+
+// segmentBitLen returns the number of bits of seg in this version,
+// -1 means seg could not be encoded in this version.
+func (v rmqrVersion) segmentBitLen(seg Segment) int {
+	ccBits := v.charCountBits(seg.Mode)
+	if seg.charCount() >= 1<<ccBits {
+		return -1
+	}
+
+	return rmqrModeIndicatorBits + ccBits + seg.dataBitLen()
+}
+
+// rmqrModeIndicatorBits and rmqrTerminatorBits are the length of mode indicator
+// and terminator of rMQR Code.
+const (
+	rmqrModeIndicatorBits = 3
+	rmqrTerminatorBits    = 3
+)
+
+// formatInfo returns the 18-bit format info placed beside finder pattern and
+// sub-finder pattern, which consists of 1 bit ECLevel and 5 bits size indicator.
+func (v rmqrVersion) formatInfo() (finderSide, subFinderSide *binary.Binary) {
+	data := uint32(v.Indicator)
+	if v.ECLevel == ErrorCorrectionHighest {
+		data |= 1 << 5
+	}
+	bits := rmqrFormatBits(data)
+
+	finderSide, subFinderSide = binary.New(), binary.New()
+	finderSide.AppendUint32(bits^rmqrFormatMaskFinder, rmqrFormatInfoBitsNum)
+	subFinderSide.AppendUint32(bits^rmqrFormatMaskSubFinder, rmqrFormatInfoBitsNum)
+
+	return finderSide, subFinderSide
+}
+
+// rmqrFormatBits appends 12 bits BCH error correction bits after 6 bits data.
+func rmqrFormatBits(data uint32) uint32 {
+	rem := data << 12
+	for i := rmqrFormatInfoBitsNum - 1; i >= 12; i-- {
+		if rem&(1<<i) != 0 {
+			rem ^= rmqrFormatGenerator << (i - 12)
+		}
+	}
+
+	return data<<12 | rem
+}
+
+// rmqrECLevel maps ec into the error correction level available in rMQR Code,
+// ErrorCorrectionLow is raised to M and ErrorCorrectionQuart is raised to H.
+func rmqrECLevel(ec ecLevel) ecLevel {
+	if ec <= ErrorCorrectionMedium {
+		return ErrorCorrectionMedium
+	}
+
+	return ErrorCorrectionHighest
+}
+
+// analyzeRMQRVersion chooses the rMQR Code size in ec with the smallest area which
+// could contain seg, height and width specify the size, 0 means any height or width.
+func analyzeRMQRVersion(height, width int, ec ecLevel, seg Segment) (*rmqrVersion, error) {
+	var hit, largest *rmqrVersion
+	for i := range rmqrVersions {
+		v := &rmqrVersions[i]
+		if v.ECLevel != ec || height != 0 && v.Height != height || width != 0 && v.Width != width {
+			continue
+		}
+		if largest == nil || v.qrVersion().NumTotalCodewords() > largest.qrVersion().NumTotalCodewords() {
+			largest = v
+		}
+
+		n := v.segmentBitLen(seg)
+		if n < 0 || n > v.qrVersion().NumTotalCodewords()*8 {
+			continue
+		}
+		if hit == nil || v.Height*v.Width < hit.Height*hit.Width {
+			hit = v
+		}
+	}
+	if hit != nil {
+		return hit, nil
+	}
+	debugLogf("mismatched rMQR version, size: R%dx%d, ec: %v", height, width, ec)
+
+	if largest == nil {
+		// no symbol has the size.
+		return nil, errAnalyzeVersionFailed
+	}
+
+	return nil, &DataTooLongError{
+		Needed:  largest.segmentBitLen(seg),
+		Max:     largest.qrVersion().NumTotalCodewords() * 8,
+		ECLevel: ec,
+		symbol:  fmt.Sprintf("R%dx%d", largest.Height, largest.Width),
+		err:     errAnalyzeVersionFailed,
+	}
+}
+
+// NewRMQR generate a rMQR Code (Rectangular Micro QR Code) from R7x43 to R17x139, which
+// fits narrow surfaces such as cable tags and test tubes. The symbol with the smallest
+// area is chosen unless WithRMQRSize is specified. Only error correction level M and H
+// are available, so ErrorCorrectionLow is raised to M and ErrorCorrectionQuart to H.
+// WithEncodingMode is supported, WithVersion, ECI, FNC1 and Structured Append are not.
+// The symbol is saved by Writer as a regular Matrix, whose width is larger than height.
+func NewRMQR[T ~string | ~[]byte](text T, opts ...EncodeOption) (*QRCode, error) {
+	dst := DefaultEncodingOption()
+	for _, opt := range opts {
+		opt.apply(dst)
+	}
+
+	qrc := &QRCode{
+		sourceText:     string(toBytes(text)),
+		encodingOption: dst,
+	}
+	if err := qrc.initRMQR(); err != nil {
+		return nil, err
+	}
+
+	qrc.maskingRMQR()
+
+	return qrc, nil
+}
+
+// initRMQR fill QRCode instance as rMQR Code from settings and sourceText.
+func (q *QRCode) initRMQR() (err error) {
+	opt := q.encodingOption
+	if opt.ECI != nil || opt.FNC1 != nil || opt.structuredAppend != nil {
+		return fmt.Errorf("init: ECI, FNC1 and Structured Append are not available in rMQR Code")
+	}
+	if opt.Verify {
+		return fmt.Errorf("init: verify is not available in rMQR Code")
+	}
+	if opt.QArt != nil {
+		return fmt.Errorf("init: QArt is not available in rMQR Code")
+	}
+	if opt.MaskPattern != nil || opt.MaskSelector != nil {
+		return fmt.Errorf("init: rMQR Code has only one mask pattern")
+	}
+
+	seg, err := q.singleSegment()
+	if err != nil {
+		return err
+	}
+
+	q.segments = []Segment{seg}
+	opt.EcLevel = rmqrECLevel(opt.EcLevel)
+	if q.rmqr, err = analyzeRMQRVersion(opt.RMQRHeight, opt.RMQRWidth, opt.EcLevel, q.segments[0]); err != nil {
+		return fmt.Errorf("init: calc rMQR version failed: %w", err)
+	}
+	q.v = q.rmqr.qrVersion()
+
+	q.encoder = newEncoder(opt.EncMode, opt.EcLevel, q.v)
+	q.encoder.rmqr = q.rmqr
+
+	dataBlocks, err := q.dataEncoding()
+	if err != nil {
+		return err
+	}
+	ecBlocks, err := q.errorCorrectionEncoding(dataBlocks)
+	if err != nil {
+		return err
+	}
+
+	q.arrangeBits(dataBlocks, ecBlocks)
+	q.dataBSet.Append(q.ecBSet)
+	q.dataBSet.AppendNumBools(q.v.RemainderBits, false)
+
+	q.mat = newMatrix(q.rmqr.Width, q.rmqr.Height)
+	q.prefillRMQRMatrix()
+
+	return nil
+}
+
+// prefillRMQRMatrix with finder, sub-finder, corner finder, alignment, timing
+// patterns and reserved format info.
+func (q *QRCode) prefillRMQRMatrix() {
+	w, h := q.rmqr.Width, q.rmqr.Height
+
+	// timing patterns are along all edges, and vertical ones cross alignment patterns.
+	for x := 0; x < w; x++ {
+		setRMQRTiming(q.mat, x, 0, x)
+		setRMQRTiming(q.mat, x, h-1, x)
+	}
+	for y := 0; y < h; y++ {
+		setRMQRTiming(q.mat, 0, y, y)
+		setRMQRTiming(q.mat, w-1, y, y)
+	}
+	for _, cx := range rmqrAlignmentColumns[w] {
+		for y := 3; y < h-3; y++ {
+			setRMQRTiming(q.mat, cx, y, y)
+		}
+		addRMQRAlignment(q.mat, cx, 1)
+		addRMQRAlignment(q.mat, cx, h-2)
+	}
+
+	addFinder(q.mat, 0, 0)
+	for y := 0; y < 7; y++ {
+		_ = q.mat.set(7, y, QRValue_SPLITTER_V0)
+	}
+	if h > 7 {
+		for x := 0; x < 8; x++ {
+			_ = q.mat.set(x, 7, QRValue_SPLITTER_V0)
+		}
+	}
+
+	// sub-finder pattern in the bottom-right corner.
+	for x := w - 5; x < w; x++ {
+		for y := h - 5; y < h; y++ {
+			// the ring 1 module away from center is light.
+			value := QRValue_FINDER_V1
+			if dx, dy := abs(x-(w-3)), abs(y-(h-3)); dx <= 1 && dy <= 1 && (dx == 1 || dy == 1) {
+				value = QRValue_FINDER_V0
+			}
+			_ = q.mat.set(x, y, value)
+		}
+	}
+
+	// corner finder patterns in the top-right and bottom-left corners.
+	_ = q.mat.set(w-1, 0, QRValue_FINDER_V1)
+	_ = q.mat.set(w-2, 0, QRValue_FINDER_V1)
+	_ = q.mat.set(w-1, 1, QRValue_FINDER_V1)
+	_ = q.mat.set(w-2, 1, QRValue_FINDER_V0)
+	if h >= 9 {
+		for x := 0; x < 3; x++ {
+			_ = q.mat.set(x, h-1, QRValue_FINDER_V1)
+		}
+	}
+	if h >= 11 {
+		_ = q.mat.set(0, h-2, QRValue_FINDER_V1)
+		_ = q.mat.set(1, h-2, QRValue_FINDER_V0)
+	}
+
+	for i := 0; i < rmqrFormatInfoBitsNum; i++ {
+		fx, fy, sx, sy := rmqrFormatInfoPos(i, w, h)
+		_ = q.mat.set(fx, fy, QRValue_FORMAT_V0)
+		_ = q.mat.set(sx, sy, QRValue_FORMAT_V0)
+	}
+}
+
+// setRMQRTiming sets timing module at (x, y), which is dark while pos is even.
+func setRMQRTiming(m *Matrix, x, y, pos int) {
+	value := QRValue_TIMING_V0
+	if pos%2 == 0 {
+		value = QRValue_TIMING_V1
+	}
+	_ = m.set(x, y, value)
+}
+
+// addRMQRAlignment adds 3x3 alignment pattern of rMQR Code, whose center is light.
+func addRMQRAlignment(m *Matrix, centerX, centerY int) {
+	for x := centerX - 1; x <= centerX+1; x++ {
+		for y := centerY - 1; y <= centerY+1; y++ {
+			_ = m.set(x, y, QRValue_ALIGNMENT_V1)
+		}
+	}
+	_ = m.set(centerX, centerY, QRValue_ALIGNMENT_V0)
+}
+
+// rmqrFormatInfoPos returns the position of format info bit i (0 is the least
+// significant bit) beside finder pattern and sub-finder pattern, in 3 columns
+// of 5 modules followed by 3 modules in a line.
+func rmqrFormatInfoPos(i, w, h int) (fx, fy, sx, sy int) {
+	if i < 15 {
+		return 8 + i/5, 1 + i%5, w - 8 + i/5, h - 6 + i%5
+	}
+
+	return 11, 1 + i - 15, w - 5 + i - 15, h - 6
+}
+
+// fillRMQRFormatInfo fills format info beside finder pattern and sub-finder pattern.
+func fillRMQRFormatInfo(m *Matrix, v *rmqrVersion) {
+	finderSide, subFinderSide := v.formatInfo()
+	for i := 0; i < rmqrFormatInfoBitsNum; i++ {
+		fx, fy, sx, sy := rmqrFormatInfoPos(i, m.Width(), m.Height())
+
+		value := QRValue_FORMAT_V0
+		if finderSide.At(rmqrFormatInfoBitsNum - 1 - i) {
+			value = QRValue_FORMAT_V1
+		}
+		_ = m.set(fx, fy, value)
+
+		value = QRValue_FORMAT_V0
+		if subFinderSide.At(rmqrFormatInfoBitsNum - 1 - i) {
+			value = QRValue_FORMAT_V1
+		}
+		_ = m.set(sx, sy, value)
+	}
+}
+
+// maskingRMQR fills data into matrix and applies the only mask pattern of
+// rMQR Code, which is (y/2 + x/3) mod 2 == 0, the same as QR Code mask 100.
+func (q *QRCode) maskingRMQR() {
+	mat := q.mat.Copy()
+	fillDataColumns(mat, q.dataBSet, mat.Width()-2)
+	q.xorMask(mat, newMask(q.mat, modulo4))
+	fillRMQRFormatInfo(mat, q.rmqr)
+
+	q.mat = mat
+	q.maskPattern = int(modulo4)
+}
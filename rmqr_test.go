@@ -0,0 +1,246 @@
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yeqown/reedsolomon"
+	"github.com/yeqown/reedsolomon/binary"
+)
+
+func Test_rmqrVersions(t *testing.T) {
+	require.Len(t, rmqrVersions, 64)
+
+	for i, v := range rmqrVersions {
+		name := fmt.Sprintf("R%dx%d-%d", v.Height, v.Width, v.ECLevel)
+		assert.Equal(t, i/2, v.Indicator, name)
+		assert.Contains(t, rmqrAlignmentColumns, v.Width, name)
+
+		// each block has the same number of error correction codewords.
+		total := 0
+		for _, g := range v.Groups {
+			assert.Equal(t, v.Groups[0].ECBlockwordsPerBlock, g.ECBlockwordsPerBlock, name)
+			total += g.NumBlocks * (g.NumDataCodewords + g.ECBlockwordsPerBlock)
+		}
+
+		// all modules left by function patterns are filled by codewords and remainder bits.
+		qrc := &QRCode{rmqr: &rmqrVersions[i], mat: newMatrix(v.Width, v.Height)}
+		qrc.prefillRMQRMatrix()
+		free := 0
+		qrc.mat.iter(IterDirection_ROW, func(x, y int, s qrvalue) {
+			if s.qrtype() == QRType_INIT {
+				free++
+			}
+		})
+		assert.Equal(t, total*8+v.RemainderBits, free, name)
+	}
+}
+
+func Test_rmqrFormatBits(t *testing.T) {
+	// BCH (18, 6) code is the same as QR Code version info.
+	for ver := 7; ver <= 40; ver++ {
+		assert.Equal(t, versionBitSequence[ver], rmqrFormatBits(uint32(ver)), "data: %d", ver)
+	}
+}
+
+func Test_analyzeRMQRVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		height, width int
+		ec            ecLevel
+		seg           Segment
+		want          string
+		wantErr       bool
+	}{
+		{
+			name: "smallest area",
+			ec:   ErrorCorrectionMedium,
+			seg:  Segment{Mode: EncModeNumeric, Data: "123"},
+			want: "R11x27",
+		},
+		{
+			name:   "specified height",
+			height: 7,
+			ec:     ErrorCorrectionMedium,
+			seg:    Segment{Mode: EncModeNumeric, Data: "123"},
+			want:   "R7x43",
+		},
+		{
+			name:   "specified size",
+			height: 13,
+			width:  99,
+			ec:     ErrorCorrectionHighest,
+			seg:    Segment{Mode: EncModeByte, Data: "abc"},
+			want:   "R13x99",
+		},
+		{
+			name:   "wider if higher is not allowed",
+			height: 7,
+			ec:     ErrorCorrectionHighest,
+			seg:    Segment{Mode: EncModeByte, Data: "abcdefghij"},
+			want:   "R7x99",
+		},
+		{
+			name:    "invalid size",
+			height:  8,
+			ec:      ErrorCorrectionMedium,
+			seg:     Segment{Mode: EncModeNumeric, Data: "123"},
+			wantErr: true,
+		},
+		{
+			name:    "too long",
+			ec:      ErrorCorrectionHighest,
+			seg:     Segment{Mode: EncModeByte, Data: strings.Repeat("a", 200)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := analyzeRMQRVersion(tt.height, tt.width, tt.ec, tt.seg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, fmt.Sprintf("R%dx%d", got.Height, got.Width))
+			assert.Equal(t, tt.ec, got.ECLevel)
+		})
+	}
+}
+
+// readRMQR reads format info and data bits from a rMQR Code matrix.
+func readRMQR(t *testing.T, m *Matrix) (v *rmqrVersion, bits *binary.Binary) {
+	t.Helper()
+
+	var finderSide, subFinderSide uint32
+	for i := rmqrFormatInfoBitsNum - 1; i >= 0; i-- {
+		fx, fy, sx, sy := rmqrFormatInfoPos(i, m.Width(), m.Height())
+		value, _ := m.at(fx, fy)
+		finderSide = finderSide<<1 | uint32(value&1)
+		value, _ = m.at(sx, sy)
+		subFinderSide = subFinderSide<<1 | uint32(value&1)
+	}
+	finderSide ^= rmqrFormatMaskFinder
+	subFinderSide ^= rmqrFormatMaskSubFinder
+	require.Equal(t, finderSide, subFinderSide)
+	require.Equal(t, rmqrFormatBits(finderSide>>12), finderSide, "invalid format info: %018b", finderSide)
+
+	data := int(finderSide >> 12)
+	ec := ErrorCorrectionMedium
+	if data>>5 == 1 {
+		ec = ErrorCorrectionHighest
+	}
+	for i := range rmqrVersions {
+		if rmqrVersions[i].Indicator == data&0x1f && rmqrVersions[i].ECLevel == ec {
+			v = &rmqrVersions[i]
+		}
+	}
+	require.NotNil(t, v)
+	require.Equal(t, v.Width, m.Width())
+	require.Equal(t, v.Height, m.Height())
+
+	// collect data modules in placement order by filling a fresh matrix.
+	layout := newMatrix(m.Width(), m.Height())
+	(&QRCode{rmqr: v, mat: layout}).prefillRMQRMatrix()
+
+	bits = binary.New()
+	upward := true
+	for right := m.Width() - 2; right >= 1; right -= 2 {
+		for i := 0; i < m.Height(); i++ {
+			y := i
+			if upward {
+				y = m.Height() - 1 - i
+			}
+			for x := right; x >= right-1; x-- {
+				if value, _ := layout.at(x, y); value.qrtype() != QRType_INIT {
+					continue
+				}
+				value, _ := m.at(x, y)
+				bits.AppendBools(value.qrbool() != modulo4Func(x, y))
+			}
+		}
+		upward = !upward
+	}
+
+	return v, bits
+}
+
+func Test_NewRMQR(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts []EncodeOption
+		size string
+		mode encMode
+	}{
+		{name: "numeric", text: "0123456789", opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionMedium)}, size: "R11x27", mode: EncModeNumeric},
+		{name: "alphanumeric in H", text: "CABLE-0042", opts: []EncodeOption{WithRMQRSize(11, 0)}, size: "R11x43", mode: EncModeAlphanumeric},
+		{name: "byte with fixed size", text: "hello rMQR", opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionLow), WithRMQRSize(17, 43)}, size: "R17x43", mode: EncModeByte},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qrc, err := NewRMQR(tt.text, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.size, fmt.Sprintf("R%dx%d", qrc.mat.Height(), qrc.mat.Width()))
+
+			v, bits := readRMQR(t, qrc.mat)
+			assert.Equal(t, qrc.rmqr, v)
+			require.Len(t, v.Groups, 1)
+			require.Equal(t, 1, v.Groups[0].NumBlocks)
+
+			// error correction codewords should match the data codewords in the only block.
+			n := v.Groups[0].NumDataCodewords * 8
+			data, _ := bits.Subset(0, n)
+			ec, _ := bits.Subset(n, n+v.Groups[0].ECBlockwordsPerBlock*8)
+			full := reedsolomon.Encode(data, v.Groups[0].ECBlockwordsPerBlock)
+			wantEC, _ := full.Subset(n, full.Len())
+			assert.True(t, wantEC.EqualTo(ec))
+
+			// mode indicator and character count.
+			pos := 0
+			readN := func(n int) uint32 {
+				var value uint32
+				for i := 0; i < n; i++ {
+					value <<= 1
+					if bits.At(pos) {
+						value |= 1
+					}
+					pos++
+				}
+				return value
+			}
+			assert.Equal(t, v.modeIndicator(tt.mode), readN(rmqrModeIndicatorBits))
+			assert.Equal(t, uint32(len(tt.text)), readN(v.charCountBits(tt.mode)))
+		})
+	}
+}
+
+func Test_NewRMQR_MultipleBlocks(t *testing.T) {
+	qrc, err := NewRMQR(strings.Repeat("rMQR", 15), WithErrorCorrectionLevel(ErrorCorrectionHighest))
+	require.NoError(t, err)
+	require.Greater(t, qrc.rmqr.qrVersion().TotalNumBlocks(), 1)
+
+	v, bits := readRMQR(t, qrc.mat)
+	assert.Equal(t, qrc.rmqr, v)
+	// data codewords are interleaved, the first one of the first block is kept at the head.
+	assert.Equal(t, "011", bitsString(bits)[:3])
+}
+
+func Test_NewRMQR_Invalid(t *testing.T) {
+	_, err := NewRMQR(strings.Repeat("a", 200))
+	assert.Error(t, err)
+
+	_, err = NewRMQR("123", WithUTF8ECI())
+	assert.Error(t, err)
+
+	_, err = NewRMQR("123", WithRMQRSize(8, 43))
+	assert.Error(t, err)
+
+	_, err = NewRMQR("abc", WithEncodingMode(EncModeNumeric))
+	assert.Error(t, err)
+}
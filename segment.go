@@ -0,0 +1,232 @@
+package qrcode
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// Segment is a run of input data which is encoded in one encMode, a QR Code
+// data bit stream consists of one or more segments, each segment starts with
+// its own mode indicator and character count indicator.
+type Segment struct {
+	// Mode of this segment, only EncModeNumeric, EncModeAlphanumeric,
+	// EncModeByte and EncModeKanji are allowed.
+	Mode encMode
+
+	// Data of this segment, Kanji segment keeps UTF-8 text here,
+	// it would be converted into Shift JIS while encoding.
+	Data string
+}
+
+// charCount returns the value of character count indicator of the segment.
+func (s Segment) charCount() int {
+	if s.Mode == EncModeKanji {
+		return utf8.RuneCountInString(s.Data)
+	}
+
+	return len(s.Data)
+}
+
+// dataBitLen returns the number of bits used by the encoded data of segment,
+// mode indicator and character count indicator are not included.
+func (s Segment) dataBitLen() int {
+	n := s.charCount()
+
+	switch s.Mode {
+	case EncModeNumeric:
+		// 3 digits in 10 bits, remaining 2 digits in 7 bits, 1 digit in 4 bits.
+		return n/3*10 + []int{0, 4, 7}[n%3]
+	case EncModeAlphanumeric:
+		// 2 characters in 11 bits, remaining 1 character in 6 bits.
+		return n/2*11 + n%2*6
+	case EncModeByte:
+		return n * 8
+	case EncModeKanji:
+		return n * 13
+	}
+
+	return 0
+}
+
+// bitLen returns the number of bits used by the segment in version ver,
+// -1 means the segment's character count overflows the character count indicator.
+func (s Segment) bitLen(ver int) int {
+	ccBits := charCountBits(ver, s.Mode)
+	if s.charCount() >= 1<<ccBits {
+		return -1
+	}
+
+	return 4 + ccBits + s.dataBitLen()
+}
+
+// segmentsBitLen returns the number of bits used by all segments in version ver,
+// -1 means any segment could not be encoded in version ver.
+func segmentsBitLen(segs []Segment, ver int) int {
+	total := 0
+	for _, s := range segs {
+		n := s.bitLen(ver)
+		if n < 0 {
+			return -1
+		}
+		total += n
+	}
+
+	return total
+}
+
+// segmentsMode returns the mode shared by all segments,
+// EncModeNone means segments are in mixed modes.
+func segmentsMode(segs []Segment) encMode {
+	if len(segs) == 0 {
+		return EncModeNone
+	}
+
+	mode := segs[0].Mode
+	for _, s := range segs[1:] {
+		if s.Mode != mode {
+			return EncModeNone
+		}
+	}
+
+	return mode
+}
+
+// versionClasses splits versions into 3 ranges, versions in the same range
+// share the same character count indicator length.
+var versionClasses = [][2]int{{1, 9}, {10, 26}, {27, 40}}
+
+// optimizeModes are the modes which optimizeSegments may choose,
+// the order decides the preferred mode while costs are equal.
+var optimizeModes = []encMode{EncModeByte, EncModeAlphanumeric, EncModeNumeric, EncModeKanji}
+
+// optimizeSegments splits raw into segments of different modes which uses the
+// fewest bits in version ver, since the length of character count indicator
+// depends on version. If fnc1 is true, Group Separator could be encoded in
+// alphanumeric mode as '%', and literal '%' takes 2 characters ('%%').
+//
+// It's a dynamic programming implementation of ISO/IEC 18004 Annex J, costs are
+// counted in 1/6 bit to avoid fraction of numeric (10/3 bits) and alphanumeric (11/2 bits).
+//
+// reference:
+// - https://www.nayuki.io/page/optimal-text-segmentation-for-qr-codes
+func optimizeSegments(raw string, ver int, fnc1 bool) []Segment {
+	if raw == "" {
+		return nil
+	}
+
+	const inf = math.MaxInt32
+
+	var (
+		numModes  = len(optimizeModes)
+		runes     = make([]rune, 0, len(raw))
+		offsets   = make([]int, 0, len(raw)+1) // byte offset of each rune in raw.
+		headCosts = make([]int, numModes)
+		prevCosts = make([]int, numModes)
+		curCosts  = make([]int, numModes)
+		// charModes[i][j] is the mode of i-th rune, while the cheapest encoding
+		// ends with mode optimizeModes[j] after i-th rune.
+		charModes [][]encMode
+	)
+
+	// invalid UTF-8 bytes are kept as single byte runes, so that they are
+	// encoded in byte mode as they are.
+	for i := 0; i < len(raw); {
+		r, size := utf8.DecodeRuneInString(raw[i:])
+		runes = append(runes, r)
+		offsets = append(offsets, i)
+		i += size
+	}
+	offsets = append(offsets, len(raw))
+	charModes = make([][]encMode, len(runes))
+
+	for j, m := range optimizeModes {
+		headCosts[j] = (4 + charCountBits(ver, m)) * 6
+	}
+	copy(prevCosts, headCosts)
+
+	for i, r := range runes {
+		charModes[i] = make([]encMode, numModes)
+		for j, m := range optimizeModes {
+			curCosts[j] = inf
+			charModes[i][j] = EncModeNone
+
+			var cost int
+			switch m {
+			case EncModeByte:
+				cost = (offsets[i+1] - offsets[i]) * 8 * 6
+			case EncModeAlphanumeric:
+				switch {
+				case fnc1 && r == '%':
+					cost = 66
+				case fnc1 && r == _GS, analyzeAlphaNum(r):
+					cost = 33
+				default:
+					continue
+				}
+			case EncModeNumeric:
+				if !analyzeNum(r) {
+					continue
+				}
+				cost = 20
+			case EncModeKanji:
+				if !analyzeJP(r) {
+					continue
+				}
+				cost = 78
+			}
+
+			curCosts[j] = prevCosts[j] + cost
+			charModes[i][j] = m
+		}
+
+		// switch to another mode after current rune if it's cheaper.
+		for j := range optimizeModes {
+			for k := range optimizeModes {
+				if charModes[i][k] == EncModeNone {
+					continue
+				}
+
+				cost := (curCosts[k]+5)/6*6 + headCosts[j]
+				if charModes[i][j] == EncModeNone || cost < curCosts[j] {
+					curCosts[j] = cost
+					charModes[i][j] = optimizeModes[k]
+				}
+			}
+		}
+
+		copy(prevCosts, curCosts)
+	}
+
+	// find the cheapest end mode, and then trace back mode of each rune.
+	cur := 0
+	for j := range optimizeModes {
+		if prevCosts[j] < prevCosts[cur] {
+			cur = j
+		}
+	}
+
+	modes := make([]encMode, len(runes))
+	curMode := optimizeModes[cur]
+	for i := len(runes) - 1; i >= 0; i-- {
+		for j, m := range optimizeModes {
+			if m == curMode {
+				curMode = charModes[i][j]
+				modes[i] = curMode
+				break
+			}
+		}
+	}
+
+	// merge consecutive runes in the same mode into one segment.
+	segs := make([]Segment, 0, 4)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && modes[i] == modes[start] {
+			continue
+		}
+		segs = append(segs, Segment{Mode: modes[start], Data: raw[offsets[start]:offsets[i]]})
+		start = i
+	}
+
+	return segs
+}
@@ -0,0 +1,172 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_segment_bitLen(t *testing.T) {
+	tests := []struct {
+		name string
+		seg  Segment
+		ver  int
+		want int
+	}{
+		{
+			name: "numeric 8 digits in version 1",
+			seg:  Segment{Mode: EncModeNumeric, Data: "01234567"},
+			ver:  1,
+			want: 4 + 10 + 27,
+		},
+		{
+			name: "alphanumeric 5 characters in version 1",
+			seg:  Segment{Mode: EncModeAlphanumeric, Data: "AC-42"},
+			ver:  1,
+			want: 4 + 9 + 28,
+		},
+		{
+			name: "byte 3 bytes in version 10",
+			seg:  Segment{Mode: EncModeByte, Data: "abc"},
+			ver:  10,
+			want: 4 + 16 + 24,
+		},
+		{
+			name: "kanji 2 characters in version 27",
+			seg:  Segment{Mode: EncModeKanji, Data: "漢字"},
+			ver:  27,
+			want: 4 + 12 + 26,
+		},
+		{
+			name: "numeric overflows character count indicator",
+			seg:  Segment{Mode: EncModeNumeric, Data: strings.Repeat("1", 1024)},
+			ver:  1,
+			want: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.seg.bitLen(tt.ver))
+		})
+	}
+}
+
+func Test_optimizeSegments(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		ver  int
+		want []Segment
+	}{
+		{
+			name: "empty",
+			raw:  "",
+			ver:  1,
+			want: nil,
+		},
+		{
+			name: "numeric only",
+			raw:  "0123456789",
+			ver:  1,
+			want: []Segment{{Mode: EncModeNumeric, Data: "0123456789"}},
+		},
+		{
+			name: "alphanumeric prefix and long numeric tail",
+			raw:  "INVOICE-2026-000123456789",
+			ver:  1,
+			want: []Segment{
+				{Mode: EncModeAlphanumeric, Data: "INVOICE-2026-"},
+				{Mode: EncModeNumeric, Data: "000123456789"},
+			},
+		},
+		{
+			name: "short numeric run stays in byte mode",
+			raw:  "abc12def",
+			ver:  1,
+			want: []Segment{{Mode: EncModeByte, Data: "abc12def"}},
+		},
+		{
+			name: "kanji and byte",
+			raw:  "漢字漢字漢字abc",
+			ver:  1,
+			want: []Segment{
+				{Mode: EncModeKanji, Data: "漢字漢字漢字"},
+				{Mode: EncModeByte, Data: "abc"},
+			},
+		},
+		{
+			name: "invalid utf-8 bytes are kept",
+			raw:  "\xff\xfe",
+			ver:  1,
+			want: []Segment{{Mode: EncModeByte, Data: "\xff\xfe"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := optimizeSegments(tt.raw, tt.ver, false)
+			assert.Equal(t, tt.want, got)
+
+			// segments must be cheaper than, or equal to the single mode encoding.
+			mode, err := analyzeEncodeModeFromRaw(tt.raw)
+			require.NoError(t, err)
+			single := segmentsBitLen([]Segment{{Mode: mode, Data: tt.raw}}, tt.ver)
+			assert.LessOrEqual(t, segmentsBitLen(got, tt.ver), single)
+		})
+	}
+}
+
+func Test_NewWith_OptimizedSegments(t *testing.T) {
+	text := "INVOICE-2026-000123 " + strings.Repeat("0", 40) + " https://example.com/x?id=9f"
+
+	plain, err := NewWith(text, WithErrorCorrectionLevel(ErrorCorrectionMedium))
+	require.NoError(t, err)
+	assert.Equal(t, EncModeByte, plain.encoder.mode)
+
+	optimized, err := NewWith(text,
+		WithErrorCorrectionLevel(ErrorCorrectionMedium),
+		WithOptimizedSegments(),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, encMode(EncModeNone), optimized.encoder.mode)
+	assert.Greater(t, len(optimized.segments), 1)
+	assert.Less(t, optimized.v.Ver, plain.v.Ver)
+}
+
+func Test_NewWith_OptimizedSegments_ExplicitMode(t *testing.T) {
+	// explicit encoding mode disables segments optimization.
+	qrc, err := NewWith("ABC123456789012",
+		WithEncodingMode(EncModeAlphanumeric),
+		WithOptimizedSegments(),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []Segment{{Mode: EncModeAlphanumeric, Data: "ABC123456789012"}}, qrc.segments)
+}
+
+func Test_analyzeVersionBySegments(t *testing.T) {
+	segs := []Segment{{Mode: EncModeNumeric, Data: strings.Repeat("1", 41)}}
+	v, err := analyzeVersionBySegments(ErrorCorrectionLow, 0, func(int) []Segment { return segs })
+	require.NoError(t, err)
+	assert.Equal(t, 1, v.Ver)
+	assert.Equal(t, ErrorCorrectionLow, v.ECLevel)
+
+	segs = []Segment{{Mode: EncModeNumeric, Data: strings.Repeat("1", 42)}}
+	v, err = analyzeVersionBySegments(ErrorCorrectionLow, 0, func(int) []Segment { return segs })
+	require.NoError(t, err)
+	assert.Equal(t, 2, v.Ver)
+
+	segs = []Segment{{Mode: EncModeByte, Data: strings.Repeat("a", 3000)}}
+	_, err = analyzeVersionBySegments(ErrorCorrectionLow, 0, func(int) []Segment { return segs })
+	assert.ErrorIs(t, err, errAnalyzeVersionFailed)
+}
+
+func Test_analyzeVersionBySegments_HeaderBits(t *testing.T) {
+	// 41 digits take 151 bits, fit in version 1-L (152 bits) only without header.
+	segs := []Segment{{Mode: EncModeNumeric, Data: strings.Repeat("1", 41)}}
+	v, err := analyzeVersionBySegments(ErrorCorrectionLow, 12, func(int) []Segment { return segs })
+	require.NoError(t, err)
+	assert.Equal(t, 2, v.Ver)
+}
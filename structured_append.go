@@ -0,0 +1,181 @@
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yeqown/reedsolomon/binary"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// _STRUCTURED_APPEND_MAX_SYMBOLS is the max number of symbols in a Structured Append sequence.
+const _STRUCTURED_APPEND_MAX_SYMBOLS = 16
+
+// structuredAppend contains fields of Structured Append header, which links
+// up to 16 symbols, so that scanners could concatenate their data in sequence.
+//
+// reference:
+// - ISO/IEC 18004:2015 Section 8
+type structuredAppend struct {
+	index  int  // index of the symbol in sequence, starts from 0.
+	total  int  // total number of symbols in sequence.
+	parity byte // parity of the whole data, XOR of all bytes in the form they are encoded.
+}
+
+// header returns Structured Append header in 20 bits:
+// mode indicator (0011), symbol sequence indicator (4 bits index, 4 bits total-1)
+// and parity data (8 bits).
+func (sa structuredAppend) header() *binary.Binary {
+	b := binary.New(false, false, true, true)
+	b.AppendUint32(uint32(sa.index), 4)
+	b.AppendUint32(uint32(sa.total-1), 4)
+	b.AppendUint32(uint32(sa.parity), 8)
+
+	return b
+}
+
+// NewStructuredAppend splits text into n (1 to 16) linked symbols in Structured
+// Append mode, each symbol could be saved by Writer as a regular QRCode and scanners
+// which support Structured Append would concatenate their data in sequence.
+// Text is split evenly by bytes at character boundaries, and opts are applied to each symbol.
+func NewStructuredAppend[T ~string | ~[]byte](text T, n int, opts ...EncodeOption) ([]*QRCode, error) {
+	if n < 1 || n > _STRUCTURED_APPEND_MAX_SYMBOLS {
+		return nil, fmt.Errorf("invalid number of Structured Append symbols: %d", n)
+	}
+
+	raw := toBytes(text)
+	chunks, err := splitStructuredAppend(raw, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildStructuredAppend(chunks, opts)
+}
+
+// NewStructuredAppendWithMaxVersion splits text into the fewest linked symbols in
+// Structured Append mode, so that each symbol's version is not larger than maxVersion.
+// An error is returned if text could not be contained in 16 symbols of maxVersion.
+func NewStructuredAppendWithMaxVersion[T ~string | ~[]byte](
+	text T, maxVersion int, opts ...EncodeOption) ([]*QRCode, error) {
+	if maxVersion < 1 || maxVersion > _VERSION_COUNT {
+		return nil, fmt.Errorf("invalid max version: %d", maxVersion)
+	}
+
+	raw := toBytes(text)
+	for n := 1; n <= _STRUCTURED_APPEND_MAX_SYMBOLS; n++ {
+		chunks, err := splitStructuredAppend(raw, n)
+		if err != nil {
+			break
+		}
+
+		fit := true
+		for idx, chunk := range chunks {
+			// prepare only chooses version, it's much cheaper than building symbol.
+			// parity doesn't change the length of header, so it's not calculated yet.
+			q := &QRCode{
+				sourceText:     string(chunk),
+				encodingOption: structuredAppendOption(opts, idx, n, 0),
+			}
+			if err = q.prepare(); err != nil || q.v.Ver > maxVersion {
+				fit = false
+				break
+			}
+		}
+
+		if fit {
+			return buildStructuredAppend(chunks, opts)
+		}
+	}
+
+	return nil, fmt.Errorf("could not split data into %d symbols of version %d",
+		_STRUCTURED_APPEND_MAX_SYMBOLS, maxVersion)
+}
+
+// buildStructuredAppend builds each chunk into a symbol in sequence.
+func buildStructuredAppend(chunks [][]byte, opts []EncodeOption) ([]*QRCode, error) {
+	// parity is calculated over segments of each chunk, since they are transcoded
+	// or converted according to options and modes.
+	var parity byte
+	for idx, chunk := range chunks {
+		q := &QRCode{
+			sourceText:     string(chunk),
+			encodingOption: structuredAppendOption(opts, idx, len(chunks), 0),
+		}
+		if err := q.prepare(); err != nil {
+			return nil, fmt.Errorf("structured append symbol[%d]: %w", idx, err)
+		}
+		parity ^= structuredAppendParity(q.segments, q.encodingOption.FNC1 != nil)
+	}
+
+	qrcs := make([]*QRCode, 0, len(chunks))
+	for idx, chunk := range chunks {
+		qrc, err := build(chunk, nil, structuredAppendOption(opts, idx, len(chunks), parity))
+		if err != nil {
+			return nil, fmt.Errorf("structured append symbol[%d]: %w", idx, err)
+		}
+		qrcs = append(qrcs, qrc)
+	}
+
+	return qrcs, nil
+}
+
+// structuredAppendOption creates a new encodingOption for each symbol,
+// since encodingOption would be modified while building symbol.
+func structuredAppendOption(opts []EncodeOption, index, total int, parity byte) *encodingOption {
+	dst := DefaultEncodingOption()
+	for _, opt := range opts {
+		opt.apply(dst)
+	}
+	dst.structuredAppend = &structuredAppend{index: index, total: total, parity: parity}
+
+	return dst
+}
+
+// fnc1AlphanumericUnescaper reverses fnc1AlphanumericEscaper.
+var fnc1AlphanumericUnescaper = strings.NewReplacer("%%", "%", "%", string(_GS))
+
+// structuredAppendParity XOR all bytes of segs in the form they are encoded: byte
+// segments as they are (transcoded by ECI already), kanji segments in Shift JIS,
+// and alphanumeric segments unescaped if they are escaped in FNC1 mode.
+func structuredAppendParity(segs []Segment, fnc1 bool) byte {
+	var parity byte
+	for _, seg := range segs {
+		data := seg.Data
+		switch {
+		case seg.Mode == EncModeKanji:
+			data, _ = japanese.ShiftJIS.NewEncoder().String(seg.Data)
+		case seg.Mode == EncModeAlphanumeric && fnc1:
+			data = fnc1AlphanumericUnescaper.Replace(seg.Data)
+		}
+
+		for i := 0; i < len(data); i++ {
+			parity ^= data[i]
+		}
+	}
+
+	return parity
+}
+
+// splitStructuredAppend splits raw into n chunks with similar length, the split
+// points are moved to UTF-8 character boundaries, so that each chunk could be
+// encoded in any mode independently.
+func splitStructuredAppend(raw []byte, n int) ([][]byte, error) {
+	chunks := make([][]byte, 0, n)
+
+	start := 0
+	for i := 1; i <= n; i++ {
+		end := len(raw) * i / n
+		for end < len(raw) && !utf8.RuneStart(raw[end]) {
+			end++
+		}
+		if end <= start {
+			return nil, fmt.Errorf("could not split %d bytes into %d symbols", len(raw), n)
+		}
+
+		chunks = append(chunks, raw[start:end])
+		start = end
+	}
+
+	return chunks, nil
+}
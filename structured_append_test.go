@@ -0,0 +1,120 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func Test_structuredAppend_header(t *testing.T) {
+	sa := structuredAppend{index: 2, total: 16, parity: 0xA5}
+	assert.Equal(t, "0011"+"0010"+"1111"+"10100101", bitsString(sa.header()))
+}
+
+func Test_splitStructuredAppend(t *testing.T) {
+	chunks, err := splitStructuredAppend([]byte("abcdefghij"), 3)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("abc"), []byte("def"), []byte("ghij")}, chunks)
+
+	// split points are moved to character boundaries.
+	chunks, err = splitStructuredAppend([]byte("日本語"), 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("日本"), []byte("語")}, chunks)
+
+	_, err = splitStructuredAppend([]byte("日本"), 3)
+	assert.Error(t, err)
+}
+
+func Test_NewStructuredAppend(t *testing.T) {
+	text := "HELLO WORLD 0123456789 hello world"
+
+	qrcs, err := NewStructuredAppend(text, 3, WithErrorCorrectionLevel(ErrorCorrectionHighest))
+	require.NoError(t, err)
+	require.Len(t, qrcs, 3)
+
+	var joined strings.Builder
+	for idx, qrc := range qrcs {
+		sa := qrc.encodingOption.structuredAppend
+		require.NotNil(t, sa)
+		assert.Equal(t, idx, sa.index)
+		assert.Equal(t, 3, sa.total)
+		assert.Equal(t, xorBytes([]byte(text)), sa.parity)
+		assert.Equal(t, ErrorCorrectionHighest, qrc.v.ECLevel)
+		// header takes the first 20 bits of data.
+		assert.Equal(t, bitsString(sa.header()), bitsString(qrc.encoder.header))
+
+		joined.WriteString(qrc.sourceText)
+	}
+	assert.Equal(t, text, joined.String())
+
+	_, err = NewStructuredAppend(text, 17)
+	assert.Error(t, err)
+	_, err = NewStructuredAppend(text, 0)
+	assert.Error(t, err)
+}
+
+// xorBytes XOR all bytes of data.
+func xorBytes(data []byte) byte {
+	var parity byte
+	for _, b := range data {
+		parity ^= b
+	}
+
+	return parity
+}
+
+func Test_structuredAppendParity(t *testing.T) {
+	sjis, err := japanese.ShiftJIS.NewEncoder().String("茗荷")
+	require.NoError(t, err)
+
+	segs := []Segment{
+		{Mode: EncModeByte, Data: "ab"},
+		{Mode: EncModeKanji, Data: "茗荷"},
+		{Mode: EncModeAlphanumeric, Data: "A%%B%C"},
+	}
+	assert.Equal(t, xorBytes([]byte("ab"+sjis+"A%%B%C")), structuredAppendParity(segs, false))
+	// escaped alphanumeric data is restored in FNC1 mode.
+	assert.Equal(t, xorBytes([]byte("ab"+sjis+"A%B\x1DC")), structuredAppendParity(segs, true))
+}
+
+func Test_NewStructuredAppend_ECITranscoding(t *testing.T) {
+	text := "Привет, мир! Съешь же ещё этих мягких французских булок"
+	qrcs, err := NewStructuredAppend(text, 2, WithECITranscoding(ECI_ISO8859_5))
+	require.NoError(t, err)
+
+	// parity is calculated over the transcoded data, which is actually encoded.
+	transcoded, err := transcodeECI(text, ECI_ISO8859_5)
+	require.NoError(t, err)
+	want := xorBytes([]byte(transcoded))
+	require.NotEqual(t, xorBytes([]byte(text)), want)
+
+	for _, qrc := range qrcs {
+		assert.Equal(t, want, qrc.encodingOption.structuredAppend.parity)
+
+		result, err := Decode(*qrc.mat)
+		require.NoError(t, err)
+		require.NotNil(t, result.StructuredAppend)
+		assert.Equal(t, want, result.StructuredAppend.Parity)
+	}
+}
+
+func Test_NewStructuredAppendWithMaxVersion(t *testing.T) {
+	// version 5-M symbol contains 84 bytes at most, and Structured Append header takes
+	// 20 bits more, so 300 bytes should be split into 4 symbols.
+	text := strings.Repeat("abcdefghij", 30)
+
+	qrcs, err := NewStructuredAppendWithMaxVersion(text, 5, WithErrorCorrectionLevel(ErrorCorrectionMedium))
+	require.NoError(t, err)
+	assert.Len(t, qrcs, 4)
+	for _, qrc := range qrcs {
+		assert.LessOrEqual(t, qrc.v.Ver, 5)
+	}
+
+	_, err = NewStructuredAppendWithMaxVersion(strings.Repeat("a", 2000), 1)
+	assert.Error(t, err)
+	_, err = NewStructuredAppendWithMaxVersion(text, 41)
+	assert.Error(t, err)
+}
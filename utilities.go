@@ -22,6 +22,14 @@ func min(x, y int) int {
 	return y
 }
 
+func max(x, y int) int {
+	if x > y {
+		return x
+	}
+
+	return y
+}
+
 func binaryToQRValueSlice(s string) []qrvalue {
 	var states = make([]qrvalue, 0, len(s))
 	for _, c := range s {
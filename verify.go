@@ -0,0 +1,47 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrVerifyFailed means the symbol built with WithVerify could not be decoded,
+// or the decoded payload differs from the source text.
+var ErrVerifyFailed = errors.New("verify failed")
+
+// verify decodes the final matrix and compares the payload with sourceText.
+func (q *QRCode) verify() error {
+	result, err := Decode(*q.mat)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrVerifyFailed, err)
+	}
+
+	if got := q.decodedPayload(result); got != q.sourceText {
+		return fmt.Errorf("%w: decoded %q, want %q", ErrVerifyFailed, got, q.sourceText)
+	}
+
+	return nil
+}
+
+// decodedPayload returns the decoded payload in the form of sourceText. result.Text
+// is not used directly, since byte segments are written as they are without transcoding
+// while WithECI is specified, they should not be converted from the declared character set.
+func (q *QRCode) decodedPayload(result *DecodeResult) string {
+	eci := q.encodingOption.ECI
+	if eci == nil || eci.transcode {
+		return result.Text
+	}
+
+	fnc1 := q.encodingOption.FNC1 != nil
+	var sb strings.Builder
+	for _, seg := range result.Segments {
+		if seg.Mode == EncModeAlphanumeric && fnc1 {
+			sb.WriteString(unescapeFNC1(seg.Data))
+			continue
+		}
+		sb.WriteString(seg.Data)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,64 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithVerify(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts []EncodeOption
+	}{
+		{name: "byte", text: "https://github.com/yeqown/go-qrcode"},
+		{name: "large version", text: strings.Repeat("verify", 100), opts: []EncodeOption{WithErrorCorrectionLevel(ErrorCorrectionHighest)}},
+		{name: "optimized segments", text: "0123456789ABCDEFGH日本語abc", opts: []EncodeOption{WithOptimizedSegments()}},
+		{name: "eci transcoding", text: "Привет", opts: []EncodeOption{WithECITranscoding(ECI_ISO8859_5)}},
+		{name: "eci without transcoding", text: "Привет", opts: []EncodeOption{WithECI(ECI_ISO8859_5)}},
+		{name: "fnc1", text: "01095011015300031726123110ABC\x1d21XYZ%", opts: []EncodeOption{WithFNC1FirstPosition(), WithOptimizedSegments()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qrc, err := NewWith(tt.text, append(tt.opts, WithVerify())...)
+			require.NoError(t, err)
+			assert.NotNil(t, qrc.mat)
+		})
+	}
+}
+
+func Test_WithVerify_StructuredAppend(t *testing.T) {
+	qrcs, err := NewStructuredAppend(strings.Repeat("0123456789abcdef", 20), 3, WithVerify())
+	require.NoError(t, err)
+	assert.Len(t, qrcs, 3)
+}
+
+func Test_QRCode_verify(t *testing.T) {
+	qrc, err := NewWith("hello verify")
+	require.NoError(t, err)
+	require.NoError(t, qrc.verify())
+
+	// payload differs from source text.
+	qrc.sourceText = "hello world"
+	assert.ErrorIs(t, qrc.verify(), ErrVerifyFailed)
+
+	// matrix could not be decoded.
+	qrc.mat.iter(IterDirection_ROW, func(x, y int, v qrvalue) {
+		if v.qrtype() == QRType_DATA {
+			qrc.mat.set(x, y, QRValue_DATA_V0)
+		}
+	})
+	assert.ErrorIs(t, qrc.verify(), ErrVerifyFailed)
+}
+
+func Test_WithVerify_NotAvailable(t *testing.T) {
+	_, err := NewMicro("123", WithVerify())
+	assert.Error(t, err)
+
+	_, err = NewRMQR("123", WithVerify())
+	assert.Error(t, err)
+}
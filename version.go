@@ -7,7 +7,6 @@ import (
 	"sync"
 
 	// "github.com/skip2/go-qrcode/bitset"
-	"unicode/utf8"
 
 	"github.com/yeqown/reedsolomon/binary"
 )
@@ -269,59 +268,39 @@ func loadVersion(lv int, ec ecLevel) version {
 	panic(errMissMatchedVersion)
 }
 
-// analyzeVersion the raw text, and then decide which version should be chosen
-// according to the text length , error correction level and encode mode to choose the
-// closest capacity of version.
-//
-// check out http://muyuchengfeng.xyz/%E4%BA%8C%E7%BB%B4%E7%A0%81-%E5%AD%97%E7%AC%A6%E5%AE%B9%E9%87%8F%E8%A1%A8/
-// for more details.
-func analyzeVersion(raw string, ec ecLevel, mode encMode) (*version, error) {
-	step := 0
-	switch ec {
-	case ErrorCorrectionLow:
-		step = 0
-	case ErrorCorrectionMedium:
-		step = 1
-	case ErrorCorrectionQuart:
-		step = 2
-	case ErrorCorrectionHighest:
-		step = 3
-	default:
+// analyzeVersionBySegments chooses the smallest version whose data capacity could
+// contain headerBits and all bits of segments. segmentsFn returns segments to encode
+// in version ver, it would be called once for each version class, since segments may
+// be split differently while the length of character count indicator changes.
+func analyzeVersionBySegments(ec ecLevel, headerBits int, segmentsFn func(ver int) []Segment) (*version, error) {
+	if ec < ErrorCorrectionLow || ec > ErrorCorrectionHighest {
 		return nil, errInvalidErrorCorrectionLevel
 	}
 
-	// Byte mode capacity is measured in bytes, not characters
-	// Numeric, Alphanumeric, and Kanji modes are character-based
-	var want int
-	if mode == EncModeByte {
-		want = len(raw)
-	} else {
-		want = utf8.RuneCountInString(raw)
-	}
-
-	mark := 0
-	for ; step < 160; step += 4 {
-
-		switch mode {
-		case EncModeNumeric:
-			mark = versions[step].Cap.Numeric
-		case EncModeAlphanumeric:
-			mark = versions[step].Cap.AlphaNumeric
-		case EncModeByte:
-			mark = versions[step].Cap.Byte
-		case EncModeKanji:
-			mark = versions[step].Cap.JP
-		default:
-			return nil, errMissMatchedEncodeType
+	need := -1
+	for _, class := range versionClasses {
+		if need = segmentsBitLen(segmentsFn(class[1]), class[1]); need < 0 {
+			continue
 		}
+		need += headerBits
 
-		if mark >= want {
-			return &versions[step], nil
+		for ver := class[0]; ver <= class[1]; ver++ {
+			v := &versions[(ver-1)*4+int(ec-ErrorCorrectionLow)]
+			if v.NumTotalCodewords()*8 >= need {
+				return v, nil
+			}
 		}
 	}
-	debugLogf("mismatched version, version's length: %d, ec: %v", len(versions), ec)
-
-	return nil, errAnalyzeVersionFailed
+	debugLogf("mismatched version by segments, ec: %v", ec)
+
+	largest := &versions[len(versions)-4+int(ec-ErrorCorrectionLow)]
+	return nil, &DataTooLongError{
+		Needed:  need,
+		Max:     largest.NumTotalCodewords() * 8,
+		Version: largest.Ver,
+		ECLevel: ec,
+		err:     errAnalyzeVersionFailed,
+	}
 }
 
 var (
@@ -64,7 +64,9 @@ func Test_loadVersion(t *testing.T) {
 	}
 }
 
-func Test_analyzeVersion(t *testing.T) {
+// Test_analyzeVersionBySegments_Capacity checks that chosen versions agree with
+// the character capacity table.
+func Test_analyzeVersionBySegments_Capacity(t *testing.T) {
 	v1 := loadVersion(1, ErrorCorrectionMedium)
 	v2 := loadVersion(5, ErrorCorrectionMedium)
 	v3 := loadVersion(23, ErrorCorrectionMedium)
@@ -110,16 +112,37 @@ func Test_analyzeVersion(t *testing.T) {
 			want:    &v3,
 			wantErr: false,
 		},
+		{
+			name: "too long",
+			args: args{
+				raw:   strings.Repeat("TEXT", 3000),
+				ecLv:  ErrorCorrectionMedium,
+				eMode: EncModeAlphanumeric,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "invalid EC level",
+			args: args{
+				raw:   "TEXT",
+				ecLv:  ecLevel(0),
+				eMode: EncModeAlphanumeric,
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := analyzeVersion(tt.args.raw, tt.args.ecLv, tt.args.eMode)
+			segs := []Segment{{Mode: tt.args.eMode, Data: tt.args.raw}}
+			got, err := analyzeVersionBySegments(tt.args.ecLv, 0, func(int) []Segment { return segs })
 			if (err != nil) != tt.wantErr {
-				t.Errorf("analyzeVersion() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("analyzeVersionBySegments() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("analyzeVersion() = %v, want %v", got, tt.want)
+				t.Errorf("analyzeVersionBySegments() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -281,26 +304,35 @@ func Benchmark_loadVersion_bottom(b *testing.B) {
 	}
 }
 
-func Benchmark_analyzeVersion_short(b *testing.B) {
+func Benchmark_analyzeVersionBySegments_short(b *testing.B) {
 	source := "text"
 
+	segs := []Segment{{Mode: EncModeByte, Data: source}}
+	segmentsFn := func(int) []Segment { return segs }
+
 	for i := 0; i < b.N; i++ {
-		_, _ = analyzeVersion(source, ErrorCorrectionMedium, EncModeByte)
+		_, _ = analyzeVersionBySegments(ErrorCorrectionMedium, 0, segmentsFn)
 	}
 }
 
-func Benchmark_analyzeVersion_middle(b *testing.B) {
+func Benchmark_analyzeVersionBySegments_middle(b *testing.B) {
 	source := strings.Repeat("text", 30)
 
+	segs := []Segment{{Mode: EncModeByte, Data: source}}
+	segmentsFn := func(int) []Segment { return segs }
+
 	for i := 0; i < b.N; i++ {
-		_, _ = analyzeVersion(source, ErrorCorrectionMedium, EncModeByte)
+		_, _ = analyzeVersionBySegments(ErrorCorrectionMedium, 0, segmentsFn)
 	}
 }
 
-func Benchmark_analyzeVersion_long(b *testing.B) {
+func Benchmark_analyzeVersionBySegments_long(b *testing.B) {
 	source := strings.Repeat("text", 300)
 
+	segs := []Segment{{Mode: EncModeByte, Data: source}}
+	segmentsFn := func(int) []Segment { return segs }
+
 	for i := 0; i < b.N; i++ {
-		_, _ = analyzeVersion(source, ErrorCorrectionMedium, EncModeByte)
+		_, _ = analyzeVersionBySegments(ErrorCorrectionMedium, 0, segmentsFn)
 	}
 }
@@ -46,7 +46,7 @@ func (w compressedWriter) Write(mat qrcode.Matrix) error {
 	padding := w.option.Padding
 	blockWidth := w.option.BlockSize
 	width := mat.Width()*blockWidth + 2*padding
-	height := width
+	height := mat.Height()*blockWidth + 2*padding
 
 	img := image.NewPaletted(
 		image.Rect(0, 0, width, height),
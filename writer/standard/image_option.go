@@ -126,14 +126,15 @@ var (
 var (
 	// _STATE_MAPPING mapping matrix.State to color.RGBA in debug mode.
 	_STATE_MAPPING = map[qrcode.QRType]color.RGBA{
-		qrcode.QRType_INIT:     parseFromHex("#ffffff"), // [bg]
-		qrcode.QRType_DATA:     parseFromHex("#cdc9c3"), // [bg]
-		qrcode.QRType_VERSION:  parseFromHex("#000000"), // [fg]
-		qrcode.QRType_FORMAT:   parseFromHex("#444444"), // [fg]
-		qrcode.QRType_FINDER:   parseFromHex("#555555"), // [fg]
-		qrcode.QRType_DARK:     parseFromHex("#2BA859"), // [fg]
-		qrcode.QRType_SPLITTER: parseFromHex("#2BA859"), // [fg]
-		qrcode.QRType_TIMING:   parseFromHex("#000000"), // [fg]
+		qrcode.QRType_INIT:      parseFromHex("#ffffff"), // [bg]
+		qrcode.QRType_DATA:      parseFromHex("#cdc9c3"), // [bg]
+		qrcode.QRType_VERSION:   parseFromHex("#000000"), // [fg]
+		qrcode.QRType_FORMAT:    parseFromHex("#444444"), // [fg]
+		qrcode.QRType_FINDER:    parseFromHex("#555555"), // [fg]
+		qrcode.QRType_DARK:      parseFromHex("#2BA859"), // [fg]
+		qrcode.QRType_SPLITTER:  parseFromHex("#2BA859"), // [fg]
+		qrcode.QRType_TIMING:    parseFromHex("#000000"), // [fg]
+		qrcode.QRType_ALIGNMENT: parseFromHex("#333333"), // [fg]
 	}
 )
 
@@ -19,6 +19,14 @@ type IShape interface {
 	DrawFinder(ctx *DrawContext)
 }
 
+// IAlignmentShape is an optional interface of IShape to draw alignment patterns in
+// its own way, alignment blocks are drawn by IShape.Draw if it's not implemented.
+type IAlignmentShape interface {
+	// DrawAlignment to fill the alignment pattern of QRCode, which helps recognizer
+	// to correct distortion in version 2 and higher.
+	DrawAlignment(ctx *DrawContext)
+}
+
 // DrawContext is a rectangle area
 type DrawContext struct {
 	*gg.Context
@@ -0,0 +1,70 @@
+package standard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (b *bufferCloser) Close() error { return nil }
+
+// renderImage saves qrc by Writer with opts into PNG image in memory.
+func renderImage(t *testing.T, qrc *qrcode.QRCode, opts ...ImageOption) image.Image {
+	t.Helper()
+
+	buf := &bufferCloser{}
+	w := NewWithWriter(buf, append(opts, WithBuiltinImageEncoder(PNG_FORMAT))...)
+	require.NoError(t, qrc.Save(w))
+
+	img, err := png.Decode(&buf.Buffer)
+	require.NoError(t, err)
+
+	return img
+}
+
+func Test_Writer_DecodeImage(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 60, 60))
+	for i := 0; i < len(logo.Pix); i += 4 {
+		logo.Pix[i], logo.Pix[i+1], logo.Pix[i+2], logo.Pix[i+3] = 0xd0, 0x30, 0x30, 0xff
+	}
+
+	tests := []struct {
+		name string
+		opts []ImageOption
+	}{
+		{name: "default"},
+		{name: "circle shape", opts: []ImageOption{WithCircleShape()}},
+		{name: "colors", opts: []ImageOption{WithBgColorRGBHex("#b8de6f"), WithFgColorRGBHex("#01c5c4")}},
+		{name: "transparent background", opts: []ImageOption{WithBgTransparent()}},
+		{name: "logo", opts: []ImageOption{WithLogoImage(logo), WithLogoSizeMultiplier(2)}},
+		{name: "logo with safe zone", opts: []ImageOption{WithLogoImage(logo), WithLogoSafeZone()}},
+		{name: "gradient", opts: []ImageOption{WithFgGradient(NewGradient(45,
+			ColorStop{T: 0, Color: color.RGBA{R: 0x10, G: 0x20, B: 0x80, A: 0xff}},
+			ColorStop{T: 1, Color: color.RGBA{R: 0x80, G: 0x10, B: 0x40, A: 0xff}},
+		))}},
+		{name: "border and width", opts: []ImageOption{WithBorderWidth(10, 20, 30, 40), WithQRWidth(7)}},
+	}
+
+	text := "https://github.com/yeqown/go-qrcode"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qrc, err := qrcode.NewWith(text, qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionHighest))
+			require.NoError(t, err)
+
+			got, err := qrcode.DecodeImage(renderImage(t, qrc, tt.opts...))
+			require.NoError(t, err)
+			assert.Equal(t, text, got.Text)
+		})
+	}
+}
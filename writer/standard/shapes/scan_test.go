@@ -0,0 +1,51 @@
+package shapes
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+	"github.com/yeqown/go-qrcode/writer/standard"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (b *bufferCloser) Close() error { return nil }
+
+func Test_Shapes_DecodeImage(t *testing.T) {
+	tests := []struct {
+		name  string
+		shape standard.IShape
+	}{
+		{name: "liquid", shape: Assemble(RoundedFinder(), LiquidBlock())},
+		{name: "chain", shape: Assemble(SquareFinder(), ChainBlock())},
+		{name: "horizontal stripe", shape: Assemble(RoundedFinder(), HStripeBlock(0.7))},
+		{name: "circle blocks", shape: Assemble(RoundedFinder(), CircleBlocks(0.8))},
+		{name: "square blocks", shape: Assemble(SquareFinder(), SquareBlocks(0.7))},
+	}
+
+	text := "https://github.com/yeqown/go-qrcode"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qrc, err := qrcode.NewWith(text, qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionHighest))
+			require.NoError(t, err)
+
+			buf := &bufferCloser{}
+			w := standard.NewWithWriter(buf,
+				standard.WithCustomShape(tt.shape), standard.WithBuiltinImageEncoder(standard.PNG_FORMAT))
+			require.NoError(t, qrc.Save(w))
+			img, err := png.Decode(&buf.Buffer)
+			require.NoError(t, err)
+
+			got, err := qrcode.DecodeImage(img)
+			require.NoError(t, err)
+			assert.Equal(t, text, got.Text)
+		})
+	}
+}
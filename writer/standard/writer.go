@@ -135,7 +135,7 @@ func draw(mat qrcode.Matrix, opt *outputImageOptions) image.Image {
 	)
 	if opt.halftoneImg != nil {
 		halftoneImg = imgkit.Binaryzation(
-			imgkit.Scale(opt.halftoneImg, image.Rect(0, 0, mat.Width()*3, mat.Width()*3), nil),
+			imgkit.Scale(opt.halftoneImg, image.Rect(0, 0, mat.Width()*3, mat.Height()*3), nil),
 			60,
 		)
 
@@ -156,10 +156,12 @@ func draw(mat qrcode.Matrix, opt *outputImageOptions) image.Image {
 
 	// bitMap stores which blocks are set (true = active block)
 	bitMap := mat.Bitmap()
-	// If the logo safe zone is enabled, clear the corresponding area in bitMap
+	// If the logo safe zone is enabled, clear the corresponding area in bitMap,
+	// function patterns such as alignment patterns are kept for recognizer.
 	if logoValid && opt.logoSafeZone {
 		mat.Iterate(qrcode.IterDirection_ROW, func(x int, y int, v qrcode.QRValue) {
-			if blockOverlapsLogo(x, y, opt.qrBlockWidth(), left, top, w, h, logoWidth, logoHeight) {
+			if v.Type() == qrcode.QRType_DATA &&
+				blockOverlapsLogo(x, y, opt.qrBlockWidth(), left, top, w, h, logoWidth, logoHeight) {
 				bitMap[x][y] = false
 			}
 		})
@@ -169,7 +171,7 @@ func draw(mat qrcode.Matrix, opt *outputImageOptions) image.Image {
 	mat.Iterate(qrcode.IterDirection_ROW, func(x int, y int, v qrcode.QRValue) {
 		// Skip drawing this block if it overlaps with the logo area.
 		// This preserves logo visibility by preventing block rendering underneath it.
-		if logoValid && opt.logoSafeZone &&
+		if logoValid && opt.logoSafeZone && v.Type() == qrcode.QRType_DATA &&
 			blockOverlapsLogo(x, y, opt.qrBlockWidth(), left, top, w, h, logoWidth, logoHeight) {
 			if v.IsSet() {
 				return
@@ -186,6 +188,12 @@ func draw(mat qrcode.Matrix, opt *outputImageOptions) image.Image {
 		switch typ := v.Type(); typ {
 		case qrcode.QRType_FINDER:
 			shape.DrawFinder(ctx)
+		case qrcode.QRType_ALIGNMENT:
+			if alignmentShape, ok := shape.(IAlignmentShape); ok {
+				alignmentShape.DrawAlignment(ctx)
+				return
+			}
+			shape.Draw(ctx)
 		case qrcode.QRType_DATA:
 			if halftoneImg == nil {
 				shape.Draw(ctx)
@@ -233,6 +241,13 @@ func draw(mat qrcode.Matrix, opt *outputImageOptions) image.Image {
 		goto done
 	}
 
+	// Log a warning if the logo covers too many codewords to be corrected,
+	// only regular QR Code could be analyzed from the matrix.
+	if report, err := qrcode.AnalyzeDamage(mat,
+		logoModuleRect(opt.qrBlockWidth(), left, top, w, h, logoWidth, logoHeight)); err == nil && !report.OK {
+		log.Printf("logo covers too many modules, QRCode may not be scanned, margin=%d \n", report.Margin)
+	}
+
 	// DONE(@yeqown): calculate the xOffset and yOffset which point(xOffset, yOffset)
 	// should icon upper-left to start
 	dc.DrawImage(opt.logoImage(), (w-logoWidth)/2, (h-logoHeight)/2)
@@ -324,6 +339,33 @@ func blockOverlapsLogo(x, y, blockSize, left, top, w, h, logoWidth, logoHeight i
 		blockBottom > logoTop && blockTop < logoBottom
 }
 
+// logoModuleRect returns the modules covered by logo, the same as blockOverlapsLogo.
+func logoModuleRect(blockSize, left, top, w, h, logoWidth, logoHeight int) image.Rectangle {
+	logoLeft := (w-logoWidth)/2 - left
+	logoTop := (h-logoHeight)/2 - top
+
+	return image.Rect(
+		floorDiv(logoLeft, blockSize), floorDiv(logoTop, blockSize),
+		ceilDiv(logoLeft+logoWidth, blockSize), ceilDiv(logoTop+logoHeight, blockSize),
+	)
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -ceilDiv(-a, b)
+	}
+
+	return a / b
+}
+
+func ceilDiv(a, b int) int {
+	if a < 0 {
+		return -floorDiv(-a, b)
+	}
+
+	return (a + b - 1) / b
+}
+
 // Attribute contains basic information of generated image.
 type Attribute struct {
 	// width and height of image
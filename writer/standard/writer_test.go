@@ -1,10 +1,13 @@
 package standard
 
 import (
+	"bytes"
 	"crypto/md5"
 	"encoding/hex"
+	"image"
 	"image/png"
 	"io"
+	"log"
 	"os"
 	"testing"
 
@@ -205,3 +208,75 @@ func Test_writer_WithBgTransparent(t *testing.T) {
 	err = qrc.Save(w)
 	assert.NoError(t, err)
 }
+
+// alignmentShape counts blocks drawn by DrawAlignment.
+type alignmentShape struct {
+	rectangle
+	alignments int
+}
+
+func (s *alignmentShape) DrawAlignment(ctx *DrawContext) {
+	s.alignments++
+	s.Draw(ctx)
+}
+
+func Test_IAlignmentShape(t *testing.T) {
+	qrc, err := qrcode.NewWith("Test_IAlignmentShape", qrcode.WithVersion(7))
+	require.NoError(t, err)
+
+	alignments := 0
+	mat := qrcode.Matrix{}
+	_ = qrc.Save(writerFunc(func(m qrcode.Matrix) error { mat = m; return nil }))
+	mat.Iterate(qrcode.IterDirection_ROW, func(x, y int, v qrcode.QRValue) {
+		if v.Type() == qrcode.QRType_ALIGNMENT {
+			alignments++
+		}
+	})
+	require.Greater(t, alignments, 0)
+
+	shape := &alignmentShape{}
+	_ = renderImage(t, qrc, WithCustomShape(shape))
+	assert.Equal(t, alignments, shape.alignments)
+
+	// alignment patterns under logo are kept even if safe zone is enabled.
+	logo := image.NewRGBA(image.Rect(0, 0, 180, 180))
+	shape = &alignmentShape{}
+	_ = renderImage(t, qrc, WithCustomShape(shape), WithLogoImage(logo), WithLogoSafeZone())
+	assert.Equal(t, alignments, shape.alignments)
+}
+
+type writerFunc func(mat qrcode.Matrix) error
+
+func (f writerFunc) Write(mat qrcode.Matrix) error { return f(mat) }
+
+func (f writerFunc) Close() error { return nil }
+
+func Test_logoModuleRect(t *testing.T) {
+	for _, tc := range []struct{ left, top, w, h, logoW, logoH int }{
+		{left: 40, top: 40, w: 580, h: 580, logoW: 100, logoH: 60},
+		{left: 0, top: 13, w: 421, h: 434, logoW: 421, logoH: 1},
+	} {
+		rect := logoModuleRect(20, tc.left, tc.top, tc.w, tc.h, tc.logoW, tc.logoH)
+		for x := -2; x < 30; x++ {
+			for y := -2; y < 30; y++ {
+				assert.Equal(t, blockOverlapsLogo(x, y, 20, tc.left, tc.top, tc.w, tc.h, tc.logoW, tc.logoH),
+					image.Pt(x, y).In(rect), "x=%d, y=%d", x, y)
+			}
+		}
+	}
+}
+
+func Test_Writer_LogoDamageWarning(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	qrc, err := qrcode.NewWith("logo damage warning", qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionHighest))
+	require.NoError(t, err)
+
+	_ = renderImage(t, qrc, WithLogoImage(image.NewRGBA(image.Rect(0, 0, 100, 100))))
+	assert.NotContains(t, buf.String(), "logo covers too many modules")
+
+	_ = renderImage(t, qrc, WithLogoImage(image.NewRGBA(image.Rect(0, 0, 250, 250))), WithLogoSizeMultiplier(2))
+	assert.Contains(t, buf.String(), "logo covers too many modules")
+}